@@ -25,12 +25,38 @@ import (
 func main() {
 	var inputPath string
 	var outputPath string
+	var configFile string
 	flag.StringVar(&inputPath, "inputPath", "", "Working directory, omit to run in current directory")
 	flag.StringVar(&outputPath, "outputPath", "", "outputPath file name with path, omit to save in current path with swagger.yaml|json")
 	flag.StringVar(&config.OutputFormat, "outputFormat", "yaml", "yaml | json: outputPath file type, default of yaml if omitted")
 	flag.StringVar(&config.AppOutputFormat, "appOutputFormat", "json", "your app's output format, default of json if omitted")
 	flag.StringVar(&config.AltFieldFormat, "altFieldFormat", "snakeCase", "snakeCase | kebabCase | camelCase | pascalCase | lowerCase | upperCase: used as alternate field formatting")
+	flag.StringVar(&config.EmbedMode, "embedMode", "flatten", "flatten | allOf: embedded structs' fields are flattened into the schema or referenced with allOf, default of flatten if omitted")
+	flag.StringVar(&config.ValidateTag, "validateTag", "validate", "struct tag of your validation rules (go-playground/validator), default of validate if omitted")
+	flag.StringVar(&config.RequiredPolicy, "requiredPolicy", "tag", "tag | omitempty: fields are required by their tags or also when they don't have omitempty, default of tag if omitted")
+	flag.Func("typeMapping", "<go type>=<name>:<value>;...: schema of a go type, i.e. github.com/acme/money.Amount=type:string;format:decimal or github.com/oklog/ulid.ULID=ref:ULID, can repeat", config.AddTypeMapping)
+	flag.StringVar(&config.GenericName, "genericName", "", "template (text/template + sprig) of the schema name of a generic struct's instantiation, i.e. Page[User] with {{.Name}}Of{{.Args | join \"And\"}} => PageOfUser, default of {{.Name}}{{.Args | join \"\"}} (PageUser) if omitted")
+	flag.BoolVar(&config.AutoStructs, "auto", false, "all of the marked structs' exported fields are in a schema named after the struct, the same as @@auto: true for each")
+	flag.StringVar(&config.SchemaNaming, "schemaNaming", "", "bare | package | path: the struct's schema names as in the sw tag (User), prefixed with the package name (billingUser) or the import path (example.com.acme.billing.User), default of bare if omitted")
+	flag.IntVar(&config.Workers, "workers", 0, "how many packages are parsed and type checked at once, default of the number of CPUs if omitted")
+	flag.Func("include", "glob of the files to scan, relative to the inputPath, i.e. api/**, can repeat, all of them if omitted", func(value string) error {
+		config.Include = append(config.Include, value)
+		return nil
+	})
+	flag.Func("exclude", "glob of the files and directories not to scan, relative to the inputPath, i.e. **/mock, can repeat", func(value string) error {
+		config.Exclude = append(config.Exclude, value)
+		return nil
+	})
+	flag.BoolVar(&config.GitIgnore, "gitignore", false, "the files ignored by the .gitignore files are not scanned")
+	flag.Func("tags", "comma separated list of build tags, for the files' build constraints (//go:build)", config.AddTags)
+	flag.StringVar(&configFile, "config", "", "config file (yaml) with typeMappings, genericName, schemaNaming, workers, include, exclude, gitignore and tags, omit to not use one")
 	flag.Parse()
+	if configFile != "" {
+		if err := config.LoadFile(configFile); err != nil {
+			fmt.Println("Error loading config file:", err)
+			return
+		}
+	}
 	if inputPath == "" {
 		wd, err := os.Getwd()
 		if err != nil {
@@ -41,9 +67,10 @@ func main() {
 	if outputPath == "" {
 		outputPath = path.Join(inputPath, "swagger."+config.OutputFormat)
 	}
-	// parse all comments and put them in a map by type
-	comments := in.ParseDirForComments(inputPath)
-	swagifyComments := in.ParseSwagifyComment(comments)
+	// parse every file once, everything is built from it
+	source := in.ScanDir(inputPath)
+	// put all comments in a map by type
+	swagifyComments := in.ParseSwagifyBlocks(source.Blocks)
 	// temp output
 	// for k, v := range swagifyComments.Types {
 	// 	fmt.Println(k, " => ")
@@ -51,11 +78,12 @@ func main() {
 	// 		fmt.Println("\t", c, " => ", u)
 	// 	}
 	// }
-	// parse for all known structs
-	myStructs := in.ParseDirForStructs(inputPath, swagifyComments.Types["struct"])
+	// all marked structs
+	myStructs, enums := source.MarkedStructs(swagifyComments.Types["struct"]), source.Enums()
 
 	// create a new openApi struct to add everything to
 	open := ope.BuildOpenApi(swagifyComments.Types["openapi"])
+	config.OpenApiVersion = open.Version
 
 	// process servers
 	servers := ser.BuildServers(swagifyComments.Types["server"])
@@ -66,15 +94,15 @@ func main() {
 	open.Security = security["openapi"]
 
 	// build schemas & parameters
-	schemas := sch.BuildSchemaStruct(myStructs)
-	sch.BuildSchema(swagifyComments.Types["schema"], schemas)
-	parameters := par.BuildParameters(swagifyComments.Types["parameter"])
+	schemas, refName := sch.BuildSchemaStruct(myStructs, enums)
+	sch.BuildSchema(swagifyComments.Types["schema"], schemas, refName)
+	parameters := par.BuildParameters(swagifyComments.Types["parameter"], enums)
 
 	// build the request body section
-	requestBodies := req.BuildRequestBody(swagifyComments.Types["requestBody"])
+	requestBodies := req.BuildRequestBody(swagifyComments.Types["requestBody"], refName)
 
 	// build the response section
-	responses := res.BuildResponse(swagifyComments.Types["response"])
+	responses := res.BuildResponse(swagifyComments.Types["response"], refName)
 
 	// build the securitySchema section
 	securitySchemes := sec.BuildSecuritySchemes(swagifyComments.Types["securityScheme"])
@@ -83,7 +111,7 @@ func main() {
 	open.Components = ope.Component{Parameters: parameters, Schemas: schemas, Responses: responses, RequestBodies: requestBodies, SecuritySchemes: securitySchemes}
 
 	// operations
-	operations := opr.BuildOperations(swagifyComments.Types["operation"])
+	operations := opr.BuildOperations(swagifyComments.Types["operation"], swagifyComments.Types["route"], source.OperationFuncs(), parameters)
 
 	// paths
 	open.Paths = pat.BuildPaths(swagifyComments.Types["path"], operations)
@@ -1,7 +1,20 @@
 package config
 
 var (
-	OutputFormat    string // json or yaml
-	AppOutputFormat string // should match your app's output format
-	AltFieldFormat  string // used for alternative field formatting: snakeCase, kebabCase, camelCase, pascalCase, upperCase, lowerCase
+	OpenApiVersion  string                     // version of the spec, from @@openapi
+	OutputFormat    string                     // json or yaml
+	AppOutputFormat string                     // should match your app's output format
+	AltFieldFormat  string                     // used for alternative field formatting: snakeCase, kebabCase, camelCase, pascalCase, upperCase, lowerCase
+	EmbedMode       string                     // how embedded structs are rendered: flatten or allOf
+	ValidateTag     string                     // struct tag with the validation rules (go-playground/validator)
+	RequiredPolicy  string                     // which fields are required: tag (sw "*" or validate) or omitempty (also the fields without omitempty)
+	TypeMappings    = map[string]TypeMapping{} // fully qualified go type, i.e. github.com/acme/money.Amount => its schema
+	GenericName     string                     // template (text/template + sprig) of the schema name of a generic struct's instantiation
+	AutoStructs     bool                       // all of the marked structs' exported fields are in a schema named after the struct, see @@auto
+	SchemaNaming    string                     // how the struct's schemas are named: bare (as in sw), package (billingUser) or path (example.com.acme.billing.User)
+	Workers         int                        // how many packages are parsed and type checked at once, 0 for the number of CPUs
+	Include         []string                   // globs of the files to scan (relative to the input path), all of them if empty
+	Exclude         []string                   // globs of the files and directories not to scan (relative to the input path)
+	GitIgnore       bool                       // the files ignored by the .gitignore files are not scanned
+	Tags            []string                   // build tags, for the files' build constraints
 )
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// the config file (-config), yaml
+	File struct {
+		TypeMappings map[string]TypeMapping `yaml:"typeMappings"`
+		GenericName  string                 `yaml:"genericName"`
+		SchemaNaming string                 `yaml:"schemaNaming"`
+		Workers      int                    `yaml:"workers"`
+		Include      []string               `yaml:"include"`
+		Exclude      []string               `yaml:"exclude"`
+		GitIgnore    bool                   `yaml:"gitignore"`
+		Tags         []string               `yaml:"tags"`
+	}
+
+	// the schema of a go type, either a reference to a schema or the type, format, pattern and example
+	TypeMapping struct {
+		Ref     string      `yaml:"ref"`
+		Type    string      `yaml:"type"`
+		Format  string      `yaml:"format"`
+		Pattern string      `yaml:"pattern"`
+		Example interface{} `yaml:"example"`
+	}
+)
+
+/*
+typeMappings:
+  github.com/acme/money.Amount:
+    type: string
+    format: decimal
+    pattern: ^\d+\.\d{2}$
+    example: "10.00"
+  github.com/oklog/ulid.ULID:
+    ref: ULID
+genericName: '{{.Name}}Of{{.Args | join "And"}}'
+schemaNaming: package
+workers: 4
+include:
+  - api/**
+exclude:
+  - internal/legacy
+gitignore: true
+tags:
+  - integration
+*/
+// the flags take precedence over the file
+func LoadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	file := File{}
+	if err := yaml.UnmarshalStrict(content, &file); err != nil {
+		return err
+	}
+	if GenericName == "" {
+		GenericName = file.GenericName
+	}
+	if SchemaNaming == "" {
+		SchemaNaming = file.SchemaNaming
+	}
+	if Workers == 0 {
+		Workers = file.Workers
+	}
+	if len(Include) == 0 {
+		Include = file.Include
+	}
+	if len(Exclude) == 0 {
+		Exclude = file.Exclude
+	}
+	GitIgnore = GitIgnore || file.GitIgnore
+	if len(Tags) == 0 {
+		Tags = file.Tags
+	}
+	for name, mapping := range file.TypeMappings {
+		if _, ok := TypeMappings[name]; !ok {
+			TypeMappings[name] = mapping
+		}
+	}
+	return nil
+}
+
+// -tags integration,linux or "integration linux", as the go tool
+func AddTags(value string) error {
+	Tags = append(Tags, strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })...)
+	return nil
+}
+
+// -typeMapping github.com/acme/money.Amount=type:string;format:decimal or github.com/oklog/ulid.ULID=ref:ULID
+func AddTypeMapping(value string) error {
+	split := strings.SplitN(value, "=", 2)
+	if len(split) != 2 || split[0] == "" {
+		return fmt.Errorf("expected <go type>=<name>:<value>;..., got: %s", value)
+	}
+	mapping := TypeMapping{}
+	for _, option := range strings.Split(split[1], ";") {
+		nameValue := strings.SplitN(option, ":", 2)
+		if len(nameValue) != 2 {
+			return fmt.Errorf("expected <name>:<value>, got: %s", option)
+		}
+		v := strings.TrimSpace(nameValue[1])
+		switch strings.TrimSpace(nameValue[0]) {
+		case "ref":
+			mapping.Ref = v
+		case "type":
+			mapping.Type = v
+		case "format":
+			mapping.Format = v
+		case "pattern":
+			mapping.Pattern = v
+		case "example":
+			mapping.Example = v
+		default:
+			return fmt.Errorf("invalid name: %s, expected ref, type, format, pattern or example", nameValue[0])
+		}
+	}
+	TypeMappings[strings.TrimSpace(split[0])] = mapping
+	return nil
+}
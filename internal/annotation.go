@@ -0,0 +1,400 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+type (
+	// @@<type>: <name> with the lines that follow it, up to an empty @@ or the end of the block
+	annotation struct {
+		compType string
+		name     string
+		lines    []string // <name>: <value>
+	}
+
+	annotationLexer struct {
+		text        string // the comment, the comment markers (/*, */, //) blanked out so the offsets are the comment's
+		pos         token.Position
+		offset      int
+		annotations []annotation
+		errs        []string
+	}
+)
+
+/*
+the annotation language, in a block comment or a run of line comments that starts with go-swagify
+
+	/* go-swagify
+	@@<type>: <name>
+	@@<name>: <value>
+	@@
+	@@<type>: <name> @@<name>: <value>
+	*\/
+
+	// go-swagify
+	// @@<type>: <name>
+	// @@<name>: <value>
+
+- @@ starts an entry at the start of a line or after a space, i.e. user@@example.com is a value
+- an empty @@ ends the <type> and its entries, the next entry is another <type>
+- a value runs up to the next entry, \@ is a @ that doesn't start an entry, the lines that continue it are joined with a space and a blank line is a new paragraph
+- a value in double quotes is kept as it is, with the quotes, an @@ in it doesn't start an entry, i.e. "a @@ b"
+- a value of | is the lines that follow it (up to the line that starts with @@) as they are, without their indentation, i.e. markdown
+- the value of an @@<name>_file is relative to the comment's source file
+the text between go-swagify and the first entry is ignored
+*/
+func lexAnnotations(text string, pos token.Position) ([]annotation, []string) {
+	l := &annotationLexer{text: blankCommentMarkers(text), pos: pos}
+	if !l.swagify() {
+		return nil, nil
+	}
+	l.entries()
+	return l.annotations, l.errs
+}
+
+// the comment (or run of line comments) is a go-swagify block
+func IsSwagifyComment(text string) bool {
+	l := &annotationLexer{text: blankCommentMarkers(text)}
+	return l.swagify()
+}
+
+func blankCommentMarkers(text string) string {
+	b := []byte(text)
+	if strings.HasPrefix(text, "/*") {
+		b[0], b[1] = ' ', ' '
+		if strings.HasSuffix(text, "*/") && len(b) >= 4 {
+			b[len(b)-2], b[len(b)-1] = ' ', ' '
+		}
+		return string(b)
+	}
+	// each line of a run of line comments, after its indentation
+	lineStart := true
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '\n':
+			lineStart = true
+		case lineStart && (b[i] == ' ' || b[i] == '\t'):
+		case lineStart && strings.HasPrefix(text[i:], "//"):
+			b[i], b[i+1] = ' ', ' '
+			lineStart = false
+		default:
+			lineStart = false
+		}
+	}
+	return string(b)
+}
+
+// the first word is go-swagify
+func (l *annotationLexer) swagify() bool {
+	l.skipSpace(true)
+	if !strings.HasPrefix(l.text[l.offset:], "go-swagify") {
+		return false
+	}
+	l.offset += len("go-swagify")
+	return l.offset == len(l.text) || unicode.IsSpace(rune(l.text[l.offset]))
+}
+
+func (l *annotationLexer) entries() {
+	// the text before the first entry
+	l.offset = l.nextEntry(l.offset)
+	var current *annotation
+	for l.offset < len(l.text) {
+		start := l.offset
+		l.offset += 2
+		if l.restOfLineEmpty() {
+			// @@, the end of the <type>
+			current = nil
+			l.offset = l.nextEntry(l.offset)
+			continue
+		}
+		name, value, ok := l.entry(start)
+		l.offset = l.nextEntry(l.offset)
+		if !ok {
+			continue
+		}
+		if current == nil {
+			if !isCompType(name) {
+				l.errorf(start, "invalid type: %s, expected letters only", name)
+				continue
+			}
+			l.annotations = append(l.annotations, annotation{compType: name, name: value, lines: []string{}})
+			current = &l.annotations[len(l.annotations)-1]
+			continue
+		}
+		if strings.HasSuffix(name, "_file") {
+			value = filePath(value, l.pos.Filename)
+		}
+		current.lines = append(current.lines, name+": "+value)
+	}
+}
+
+// <name>: <value> of the entry that starts at start (@@)
+func (l *annotationLexer) entry(start int) (name, value string, ok bool) {
+	nameStart := l.offset
+	for l.offset < len(l.text) && isNameChar(l.text[l.offset]) {
+		l.offset++
+	}
+	name = l.text[nameStart:l.offset]
+	l.skipSpace(false)
+	if name == "" || l.offset == len(l.text) || l.text[l.offset] != ':' {
+		l.errorf(start, "bad format of line: %s, expected @@<name>: <value>", l.line(start))
+		return "", "", false
+	}
+	l.offset++
+	l.skipSpace(false)
+	switch {
+	case l.blockScalar():
+		value, ok = l.block(), true
+	case l.offset < len(l.text) && l.text[l.offset] == '"':
+		value, ok = l.quoted(), true
+	default:
+		value, ok = l.unquoted(), true
+	}
+	if ok && value == "" {
+		l.errorf(start, "no value for @@%s", name)
+		return "", "", false
+	}
+	return name, value, ok
+}
+
+// up to the next entry, the lines that continue it are joined with a space, a blank line is a new paragraph
+func (l *annotationLexer) unquoted() string {
+	end := l.nextEntry(l.offset)
+	var value strings.Builder
+	blank := false
+	for _, line := range strings.Split(l.text[l.offset:end], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			blank = value.Len() > 0
+			continue
+		}
+		if value.Len() > 0 {
+			if blank {
+				value.WriteString("\n\n")
+			} else {
+				value.WriteString(" ")
+			}
+		}
+		blank = false
+		value.WriteString(line)
+	}
+	l.offset = end
+	return strings.ReplaceAll(value.String(), `\@`, "@")
+}
+
+// | (or |-) and nothing else on the line, the value is the lines that follow it
+func (l *annotationLexer) blockScalar() bool {
+	start := l.offset
+	for _, indicator := range []string{"|-", "|"} {
+		if strings.HasPrefix(l.text[l.offset:], indicator) {
+			l.offset += len(indicator)
+			if l.restOfLineEmpty() {
+				return true
+			}
+			break
+		}
+	}
+	l.offset = start
+	return false
+}
+
+/*
+the lines up to the line that starts with the next entry, as they are (i.e. markdown), without the
+indentation of the first one, an @@ in the middle of a line doesn't end it
+*/
+func (l *annotationLexer) block() string {
+	end := len(l.text)
+	for i := l.offset; i < len(l.text); i++ {
+		if l.text[i] != '\n' {
+			continue
+		}
+		if line := strings.TrimLeft(l.text[i+1:], " \t"); strings.HasPrefix(line, "@@") {
+			end = len(l.text) - len(line)
+			break
+		}
+	}
+	lines := strings.Split(strings.TrimPrefix(l.text[l.offset:end], "\n"), "\n")
+	l.offset = end
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	indent := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+			continue
+		}
+		if indent == -1 {
+			indent = len(line) - len(strings.TrimLeft(line, " \t"))
+		}
+		trimmed := 0
+		for trimmed < indent && (line[trimmed] == ' ' || line[trimmed] == '\t') {
+			trimmed++
+		}
+		lines[i] = strings.TrimRight(line[trimmed:], " \t")
+	}
+	return strings.ReplaceAll(strings.Join(lines, "\n"), `\@`, "@")
+}
+
+/*
+a value that is all in double quotes (the closing quote ends it) is kept as it is, with its quotes, an @@ in it
+doesn't start an entry, \" doesn't end it, i.e. "a @@ b", any other value that starts with a quote is unquoted,
+i.e. "Quoted" is the first word
+*/
+func (l *annotationLexer) quoted() string {
+	start := l.offset
+	for i := start + 1; i < len(l.text); i++ {
+		switch l.text[i] {
+		case '\\':
+			i++
+		case '"':
+			if end := l.nextEntry(i + 1); strings.TrimSpace(l.text[i+1:end]) == "" {
+				l.offset = end
+				return l.text[start : i+1]
+			}
+			return l.unquoted()
+		}
+	}
+	return l.unquoted()
+}
+
+// the offset of the next @@ that starts an entry: at the start of a line or after a space, not escaped (\@@)
+func (l *annotationLexer) nextEntry(offset int) int {
+	for i := offset; i < len(l.text)-1; i++ {
+		if l.text[i] == '\\' {
+			i++
+			continue
+		}
+		if l.text[i] == '@' && l.text[i+1] == '@' && (i == 0 || unicode.IsSpace(rune(l.text[i-1]))) {
+			return i
+		}
+	}
+	return len(l.text)
+}
+
+func (l *annotationLexer) restOfLineEmpty() bool {
+	end := strings.IndexByte(l.text[l.offset:], '\n')
+	if end == -1 {
+		end = len(l.text) - l.offset
+	}
+	if strings.TrimSpace(l.text[l.offset:l.offset+end]) != "" {
+		return false
+	}
+	l.offset += end
+	return true
+}
+
+func (l *annotationLexer) skipSpace(newLines bool) {
+	for l.offset < len(l.text) && (l.text[l.offset] == ' ' || l.text[l.offset] == '\t' || (newLines && unicode.IsSpace(rune(l.text[l.offset])))) {
+		l.offset++
+	}
+}
+
+// the line of the offset, for the messages
+func (l *annotationLexer) line(offset int) string {
+	end := strings.IndexByte(l.text[offset:], '\n')
+	if end == -1 {
+		return strings.TrimSpace(l.text[offset:])
+	}
+	return strings.TrimSpace(l.text[offset : offset+end])
+}
+
+func (l *annotationLexer) errorf(offset int, format string, args ...interface{}) {
+	l.errs = append(l.errs, fmt.Sprintf("%s: %s", l.position(offset), fmt.Sprintf(format, args...)))
+}
+
+// file:line:column of the offset in the comment
+func (l *annotationLexer) position(offset int) string {
+	pos := l.pos
+	if pos.Line == 0 {
+		pos.Line, pos.Column = 1, 1
+	}
+	if newLine := strings.LastIndexByte(l.text[:offset], '\n'); newLine > -1 {
+		pos.Line += strings.Count(l.text[:offset], "\n")
+		pos.Column = offset - newLine
+	} else {
+		pos.Column += offset
+	}
+	return pos.String()
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '.' || c == '/' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isCompType(name string) bool {
+	for _, c := range name {
+		if !unicode.IsLetter(c) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+the comments of a group as blocks, a block comment is a block, so is a run of line comments,
+a line comment that starts with go-swagify starts a block of its own, i.e. below a doc comment,
+and after its entries a line comment that is not indented and not an entry ends it, i.e. a doc comment below it
+
+	// go-swagify
+	// @@struct: User
+	//   continues the value
+	// User is a user.
+*/
+func commentBlocks(group *ast.CommentGroup) (blocks [][]*ast.Comment) {
+	if group == nil {
+		return
+	}
+	var run []*ast.Comment
+	for _, c := range group.List {
+		if strings.HasPrefix(c.Text, "/*") {
+			if len(run) > 0 {
+				blocks, run = append(blocks, run), nil
+			}
+			blocks = append(blocks, []*ast.Comment{c})
+			continue
+		}
+		if len(run) > 0 && (IsSwagifyComment(c.Text) || endsEntries(run, c.Text)) {
+			blocks, run = append(blocks, run), nil
+		}
+		run = append(run, c)
+	}
+	if len(run) > 0 {
+		blocks = append(blocks, run)
+	}
+	return
+}
+
+// the line comment is text after the entries of a go-swagify run, not an entry nor a value's indented continuation
+func endsEntries(run []*ast.Comment, text string) bool {
+	line := strings.TrimPrefix(strings.TrimPrefix(text, "//"), " ")
+	if strings.TrimSpace(line) == "" || line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(line, "@@") {
+		return false
+	}
+	if !IsSwagifyComment(run[0].Text) {
+		return false
+	}
+	// the text between go-swagify and the first entry is ignored, it doesn't end the block
+	for _, c := range run {
+		if strings.Contains(c.Text, "@@") {
+			return true
+		}
+	}
+	return false
+}
+
+// the text of the block, with fset the line comments are indented to their columns so the positions are right
+func blockText(comments []*ast.Comment, fset *token.FileSet) string {
+	lines := make([]string, len(comments))
+	for i, c := range comments {
+		lines[i] = c.Text
+		if i > 0 && fset != nil {
+			lines[i] = strings.Repeat(" ", fset.Position(c.Pos()).Column-1) + c.Text
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lexAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		annotations []annotation
+		errs        []string
+	}{
+		{
+			"block",
+			"/* go-swagify\n@@struct: User\n@@auto: true\n@@\n@@schema: Error @@type: object\n*/",
+			[]annotation{{"struct", "User", []string{"auto: true"}}, {"schema", "Error", []string{"type: object"}}},
+			nil,
+		},
+		{
+			"line comments",
+			"// go-swagify\n// @@operation: /user/{id}\n\t// @@method: get",
+			[]annotation{{"operation", "/user/{id}", []string{"method: get"}}},
+			nil,
+		},
+		{
+			"not go-swagify",
+			"// go-swagifying is fun\n// @@struct: User",
+			nil,
+			nil,
+		},
+		{
+			"@@ in values",
+			"/* go-swagify\n@@schema: Contact\n@@example: user@@example.com\n@@description: a \\@@ b\n@@pattern: ^\\d+@@$\n*/",
+			[]annotation{{"schema", "Contact", []string{"example: user@@example.com", "description: a @@ b", `pattern: ^\d+@@$`}}},
+			nil,
+		},
+		{
+			"quoted",
+			"/* go-swagify\n@@schema: Quote\n@@description: \"say \\\"hi\\\" @@ them\\nplease\"   @@type: string\n*/",
+			[]annotation{{"schema", "Quote", []string{`description: "say \"hi\" @@ them\nplease"`, "type: string"}}},
+			nil,
+		},
+		{
+			"quotes as before",
+			"/* go-swagify\n@@response: User\n@@desc: \"Quoted\" is the first word here\n@@content_name: application/json\n@@\n@@schema: Greeting\n@@prop_ex: \"hello\"\n@@prop_name: say \"open\n*/",
+			[]annotation{
+				{"response", "User", []string{`desc: "Quoted" is the first word here`, "content_name: application/json"}},
+				{"schema", "Greeting", []string{`prop_ex: "hello"`, `prop_name: say "open`}},
+			},
+			nil,
+		},
+		{
+			"continuation lines",
+			"/* go-swagify\n@@operation: /user\n@@description: Get the user\n  by its id.\n\n  It has to be active.\n@@method: get\n*/",
+			[]annotation{{"operation", "/user", []string{"description: Get the user by its id.\n\nIt has to be active.", "method: get"}}},
+			nil,
+		},
+		{
+			"block scalar",
+			"/* go-swagify\n@@operation: /user\n@@description: |\n\t| code | meaning |\n\t|------|---------|\n\t| 404  | missing @@ |\n\n\t    - indented\n  @@method: get\n*/",
+			[]annotation{{"operation", "/user", []string{"description: | code | meaning |\n|------|---------|\n| 404  | missing @@ |\n\n    - indented", "method: get"}}},
+			nil,
+		},
+		{
+			"block scalar in line comments",
+			"// go-swagify\n// @@schema: Note\n// @@description: |-\n//   # Title\n//\n//   text\n// @@type: string",
+			[]annotation{{"schema", "Note", []string{"description: # Title\n\ntext", "type: string"}}},
+			nil,
+		},
+		{
+			"errors",
+			"/* go-swagify\n@@schema: Bad\n@@no colon\n  @@description: \"open\n@@\n@@type 1: x\n*/",
+			[]annotation{{"schema", "Bad", []string{`description: "open`}}},
+			[]string{
+				"user.go:11:1: bad format of line: @@no colon, expected @@<name>: <value>",
+				"user.go:14:1: bad format of line: @@type 1: x, expected @@<name>: <value>",
+			},
+		},
+		{
+			"invalid type",
+			"/* go-swagify @@2xx: Bad */",
+			nil,
+			[]string{"user.go:9:18: invalid type: 2xx, expected letters only"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations, errs := lexAnnotations(tt.text, token.Position{Filename: "user.go", Line: 9, Column: 4})
+			assert.Equal(t, tt.annotations, annotations)
+			assert.Equal(t, tt.errs, errs)
+		})
+	}
+}
@@ -16,6 +16,11 @@ func TestParseSwagifyComment(t *testing.T) {
 			[]string{"/* go-swagify\n@@test: name1\n@@prop: prop_name\n@@\n@@again: name2\n@@another_prop: doh\n*/"},
 			Component{Types: map[string]SwagifyComment{"test": {map[string][][]string{"name1": {{"prop: prop_name"}}}}, "again": {map[string][][]string{"name2": {{"another_prop: doh"}}}}}},
 		},
+		{
+			"quoted values",
+			[]string{"/* go-swagify\n@@response: User\n@@desc: \"Quoted\" is the first word here\n@@\n@@schema: Greeting\n@@prop_ex: \"hello\"\n*/"},
+			Component{Types: map[string]SwagifyComment{"response": {map[string][][]string{"User": {{`desc: "Quoted" is the first word here`}}}}, "schema": {map[string][][]string{"Greeting": {{`prop_ex: "hello"`}}}}}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
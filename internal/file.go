@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// the path of an @@<name>_file relative to the source file of the comment, i.e. docs/orders.md
+func filePath(value, sourceFile string) string {
+	if filepath.IsAbs(value) || sourceFile == "" {
+		return value
+	}
+	return filepath.Join(filepath.Dir(sourceFile), value)
+}
+
+// the content of an @@description_file, i.e. markdown
+func FileDescription(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// the content of an @@example_file, a json file is the value it holds (object, array, ...), anything else is a string
+func FileExample(path string) (interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var example interface{}
+		if err := json.Unmarshal(content, &example); err != nil {
+			return nil, err
+		}
+		return example, nil
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
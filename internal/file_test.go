@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileExample(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"testdata/user.json": `{"id": 101, "tags": ["a", "b"], "active": true}`,
+		"testdata/user.txt":  "id=101\n",
+		"testdata/bad.json":  `{"id": `,
+	})
+	example, err := FileExample(filepath.Join(dir, "testdata/user.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"id": float64(101), "tags": []interface{}{"a", "b"}, "active": true}, example)
+	example, err = FileExample(filepath.Join(dir, "testdata/user.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "id=101", example)
+	_, err = FileExample(filepath.Join(dir, "testdata/bad.json"))
+	assert.NotNil(t, err)
+	_, err = FileExample(filepath.Join(dir, "testdata/missing.json"))
+	assert.NotNil(t, err)
+}
+
+func Test_filePath(t *testing.T) {
+	annotations, _ := lexAnnotations("/* go-swagify\n@@operation: /orders\n@@description_file: ../docs/orders.md\n@@example_file: /abs/order.json\n*/", token.Position{Filename: "/src/api/handler/orders.go", Line: 1, Column: 1})
+	assert.Equal(t, []annotation{{"operation", "/orders", []string{"description_file: /src/api/docs/orders.md", "example_file: /abs/order.json"}}}, annotations)
+}
@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"go/build"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/blackflagsoftware/go-swagify/config"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+)
+
+// the directories that are never scanned, wherever they are
+var skippedDirs = map[string]struct{}{"vendor": {}, "testdata": {}, "node_modules": {}, ".git": {}}
+
+type (
+	// a line of a .gitignore
+	ignoreRule struct {
+		base    string // directory of the .gitignore, relative to the scanned directory
+		pattern string
+		negate  bool // !pattern
+		dirOnly bool // pattern/
+	}
+)
+
+// a bad glob matches nothing, warn about it once
+func checkGlobs() {
+	for _, glob := range append(append([]string{}, config.Include...), config.Exclude...) {
+		for _, segment := range strings.Split(glob, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				perr.AddError(fmt.Sprintf("[Warning] invalid glob: %s", glob))
+				break
+			}
+		}
+	}
+}
+
+// a directory that is skipped with all that's in it, see skipFile
+func skipDir(rel string, rules []ignoreRule) bool {
+	if _, ok := skippedDirs[path.Base(rel)]; ok {
+		return true
+	}
+	return matchAny(config.Exclude, rel) || ignored(rules, rel, true)
+}
+
+/*
+which files are scanned, by their path relative to the scanned directory (i.e. api/user/handler.go)
+- never the directories vendor, testdata, node_modules and .git, nor the _test.go files
+- not the ones that match an -exclude glob (a directory that matches is skipped with all that's in it)
+- only the ones that match an -include glob, if there are any
+- not the ones ignored by a .gitignore, with -gitignore
+- only the ones the build constraints (//go:build, _linux.go, ...) allow, with the -tags
+a glob is path.Match's pattern with ** for any number of directories, i.e. api/** or internal/legacy
+*/
+func skipFile(directory, rel string, rules []ignoreRule) (bool, error) {
+	name := path.Base(rel)
+	if path.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+		return true, nil
+	}
+	if matchAny(config.Exclude, rel) || (len(config.Include) > 0 && !matchAny(config.Include, rel)) || ignored(rules, rel, false) {
+		return true, nil
+	}
+	ctxt := build.Default
+	ctxt.BuildTags = config.Tags
+	match, err := ctxt.MatchFile(directory, name)
+	return !match, err
+}
+
+func matchAny(globs []string, rel string) bool {
+	for _, glob := range globs {
+		if matchGlob(glob, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlob(glob, rel string) bool {
+	return matchSegments(strings.Split(strings.Trim(glob, "/"), "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(globs, names []string) bool {
+	for len(globs) > 0 {
+		if globs[0] == "**" {
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(globs[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(globs[0], names[0]); !ok {
+			return false
+		}
+		globs, names = globs[1:], names[1:]
+	}
+	return len(names) == 0
+}
+
+// the rules of the directory's .gitignore, if it has one
+func gitIgnoreRules(directory, rel string) (rules []ignoreRule) {
+	f, err := os.Open(path.Join(directory, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: rel}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return
+}
+
+// the last rule that matches decides, as git does
+func ignored(rules []ignoreRule, rel string, isDir bool) (ignore bool) {
+	for _, rule := range rules {
+		if rule.match(rel, isDir) {
+			ignore = !rule.negate
+		}
+	}
+	return
+}
+
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, r.base+"/")
+	}
+	if !strings.Contains(r.pattern, "/") {
+		// any file or directory of the name, at any depth
+		ok, _ := path.Match(r.pattern, path.Base(rel))
+		return ok
+	}
+	return matchGlob(r.pattern, rel)
+}
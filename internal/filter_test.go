@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_matchGlob(t *testing.T) {
+	tests := []struct {
+		glob  string
+		rel   string
+		match bool
+	}{
+		{"api/**", "api/user/handler.go", true},
+		{"api/**", "api", true},
+		{"api/*.go", "api/user/handler.go", false},
+		{"**/mock", "internal/user/mock", true},
+		{"**/*_gen.go", "model_gen.go", true},
+		{"internal/**/mock/*.go", "internal/mock/user.go", true},
+		{"internal/legacy/", "internal/legacy", true},
+		{"internal", "internal/legacy", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.match, matchGlob(tt.glob, tt.rel), "%s %s", tt.glob, tt.rel)
+	}
+}
+
+func Test_ignored(t *testing.T) {
+	rules := []ignoreRule{
+		{pattern: "gen"},
+		{pattern: "*.pb.go"},
+		{pattern: "build", dirOnly: true},
+		{base: "api", pattern: "old/*.go"},
+		{base: "api", pattern: "keep.pb.go", negate: true},
+	}
+	tests := []struct {
+		rel    string
+		isDir  bool
+		ignore bool
+	}{
+		{"gen", true, true},
+		{"api/gen", true, true},
+		{"user/user.pb.go", false, true},
+		{"api/keep.pb.go", false, false},
+		{"build", false, false},
+		{"build", true, true},
+		{"api/old/user.go", false, true},
+		{"old/user.go", false, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.ignore, ignored(rules, tt.rel, tt.isDir), tt.rel)
+	}
+}
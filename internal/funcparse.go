@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"go/token"
+	"strings"
+)
+
+type (
+	// a func with an @@operation block directly above it
+	Func struct {
+		Name string // name of the func, without the receiver
+		Doc  string // doc comment of the func, without the go-swagify comments
+		Pos  string // file:line of the func
+		// the path and method of each of its operations, see OperationKey
+		Operations []string
+	}
+)
+
+// the path and method of each @@operation (and @@route) in the comment, a comment can have more than one
+func operationKeys(comment string) (keys []string) {
+	// the messages are added when the comments are parsed
+	annotations, _ := lexAnnotations(comment, token.Position{})
+	for _, a := range annotations {
+		if fields := strings.Fields(a.name); a.compType == "route" && len(fields) > 1 {
+			// @@route: GET /user/{id} ...
+			keys = append(keys, OperationKey(fields[1], strings.ToLower(fields[0])))
+			continue
+		}
+		if a.compType != "operation" {
+			continue
+		}
+		for _, line := range a.lines {
+			if split := strings.SplitN(line, ":", 2); len(split) == 2 && split[0] == "method" {
+				keys = append(keys, OperationKey(a.name, split[1]))
+			}
+		}
+	}
+	return
+}
+
+func OperationKey(path, method string) string {
+	return strings.TrimSpace(path) + " " + strings.TrimSpace(method)
+}
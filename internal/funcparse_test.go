@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource_OperationFuncs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"handler/user.go": `package handler
+
+// GetUser returns the user by its id.
+// The user must exist.
+/* go-swagify
+@@operation: /user/{id}
+@@method: get
+@@resp_name: 200
+@@resp_ref: User
+*/
+func GetUser() {}
+
+/* go-swagify
+@@operation: /user
+@@method: post
+@@
+@@operation: /user
+@@method: put
+*/
+func SaveUser() {}
+
+// NoBlock is not an operation.
+func NoBlock() {}
+
+// ListUsers returns the users.
+// go-swagify
+// @@operation: /users
+// @@method: get
+func ListUsers() {}
+`,
+	})
+	funcs := ScanDir(dir).OperationFuncs()
+	assert.Len(t, funcs, 4)
+	get := funcs[OperationKey("/user/{id}", "get")]
+	assert.Equal(t, "GetUser", get.Name)
+	assert.Equal(t, "GetUser returns the user by its id.\nThe user must exist.", get.Doc)
+	assert.True(t, strings.HasSuffix(get.Pos, "handler/user.go:11"), get.Pos)
+	assert.Equal(t, "SaveUser", funcs[OperationKey("/user", "post")].Name)
+	assert.Equal(t, "SaveUser", funcs[OperationKey("/user", "put")].Name)
+	list := funcs[OperationKey("/users", "get")]
+	assert.Equal(t, "ListUsers", list.Name)
+	assert.Equal(t, "ListUsers returns the users.", list.Doc)
+}
@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/blackflagsoftware/go-swagify/config"
+)
+
+type (
+	// a package as listed by go list -json
+	listedPackage struct {
+		Dir        string
+		ImportPath string
+		Name       string
+		Export     string            // file with the package's export data
+		GoFiles    []string          // names of the package's files, in Dir
+		CgoFiles   []string          // names of the package's files that import "C", in Dir
+		ImportMap  map[string]string // import path (as in the source) => package path, i.e. vendored
+		DepOnly    bool              // not a scanned directory's package, only imported
+		Error      *struct {
+			Err string
+		}
+
+		// a scanned directory's package, type checked from source by scanGoDir
+		types *types.Package
+		done  chan struct{} // closed once the package is type checked
+	}
+
+	// the packages of the scanned directories and all of their imports
+	packageList struct {
+		pkgs  map[string]*listedPackage // by import path
+		roots map[string]*listedPackage // the scanned directories' packages, by directory
+		order []string                  // the roots' directories, a package after the ones it imports
+		// the packages that are not scanned are imported from their export data
+		exports *syncImporter
+	}
+)
+
+/*
+list the packages of the directories, with go list from the directory so they are the ones of the directory's
+module wherever the tool is run, a scanned package is type checked once, from source, the packages it imports
+that are not scanned come from their export data (built by go list -export, fast once they are in the build cache),
+only those are built, -export on the scanned packages would compile them too
+*/
+func listPackages(fset *token.FileSet, directory string, dirs []goDir) (*packageList, error) {
+	list := &packageList{pkgs: make(map[string]*listedPackage), roots: make(map[string]*listedPackage)}
+	list.exports = &syncImporter{importer: importer.ForCompiler(fset, "gc", list.lookup).(types.ImporterFrom)}
+	scanned := make(map[string]struct{})
+	paths := []string{}
+	for _, dir := range dirs {
+		if len(dir.files) == 0 {
+			continue
+		}
+		absDir, err := filepath.Abs(dir.directory)
+		if err != nil {
+			return nil, err
+		}
+		scanned[absDir] = struct{}{}
+		paths = append(paths, absDir)
+	}
+	if len(scanned) == 0 {
+		return list, nil
+	}
+	pkgs, err := goList(directory, "-deps", paths)
+	if err != nil {
+		return nil, err
+	}
+	deps := []string{}
+	for _, pkg := range pkgs {
+		list.pkgs[pkg.ImportPath] = pkg
+		// -deps lists a package after the ones it imports
+		if _, ok := scanned[pkg.Dir]; ok && !pkg.DepOnly && pkg.Error == nil {
+			pkg.done = make(chan struct{})
+			list.roots[pkg.Dir] = pkg
+			list.order = append(list.order, pkg.Dir)
+		} else if pkg.ImportPath != "unsafe" {
+			deps = append(deps, pkg.ImportPath)
+		}
+	}
+	if len(deps) == 0 {
+		return list, nil
+	}
+	exported, err := goList(directory, "-export", deps)
+	if err != nil {
+		// the imports are not resolved, the scanned packages are still type checked
+		return list, nil
+	}
+	for _, pkg := range exported {
+		if listed, ok := list.pkgs[pkg.ImportPath]; ok {
+			listed.Export = pkg.Export
+		}
+	}
+	return list, nil
+}
+
+// the packages listed by go list -e -json, from the directory, with the flag (i.e. -deps)
+func goList(directory, flag string, paths []string) ([]*listedPackage, error) {
+	args := []string{"list", "-e", "-json", flag}
+	if len(config.Tags) > 0 {
+		args = append(args, "-tags="+strings.Join(config.Tags, ","))
+	}
+	cmd := exec.Command("go", append(args, paths...)...)
+	cmd.Dir = directory
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// with -e the packages are listed even when some of them can't be built, the error is the output's
+	runErr := cmd.Run()
+	pkgs := []*listedPackage{}
+	decoder := json.NewDecoder(&stdout)
+	for {
+		pkg := &listedPackage{}
+		if err := decoder.Decode(pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	if runErr != nil && len(pkgs) == 0 {
+		return nil, fmt.Errorf("%s: %s", runErr, strings.TrimSpace(stderr.String()))
+	}
+	return pkgs, nil
+}
+
+// the listed package of the directory, nil if it isn't one (i.e. it has more than one package)
+func (l *packageList) root(directory string) *listedPackage {
+	if l == nil {
+		return nil
+	}
+	absDir, err := filepath.Abs(directory)
+	if err != nil {
+		return nil
+	}
+	return l.roots[absDir]
+}
+
+// the importer of the package's imports: the scanned packages once they are type checked, the others from export data
+func (l *packageList) importer(pkg *listedPackage) types.Importer {
+	return importerFunc(func(path string) (*types.Package, error) {
+		if mapped, ok := pkg.ImportMap[path]; ok {
+			path = mapped
+		}
+		if imported, ok := l.pkgs[path]; ok && imported.done != nil {
+			<-imported.done
+			return imported.types, nil
+		}
+		return l.exports.Import(path)
+	})
+}
+
+func (l *packageList) lookup(path string) (io.ReadCloser, error) {
+	pkg, ok := l.pkgs[path]
+	if !ok || pkg.Export == "" {
+		return nil, fmt.Errorf("no export data for %s", path)
+	}
+	return os.Open(pkg.Export)
+}
+
+// the package's files, their names in its directory
+func (p *listedPackage) files() []string {
+	return append(append([]string{}, p.GoFiles...), p.CgoFiles...)
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// the export data importer shared by all of the packages, it can only import one package at a time
+type syncImporter struct {
+	mu       sync.Mutex
+	importer types.ImporterFrom
+}
+
+func (i *syncImporter) Import(path string) (*types.Package, error) {
+	return i.ImportFrom(path, "", 0)
+}
+
+func (i *syncImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.importer.ImportFrom(path, dir, mode)
+}
@@ -20,6 +20,7 @@ import (
 @@openapi: (required) 3.+
 @@info.title: (requrired)
 @@info.description: (optional)
+@@info.description_file: (optional) file with the description (i.e. markdown), relative to the source file
 @@info.termsOfService: (optional)
 @@info.contact: (optional)
 @@info.license: (optional)
@@ -67,7 +68,10 @@ type (
 
 func BuildOpenApi(comments in.SwagifyComment) OpenApi {
 	open := &OpenApi{Version: "3.0.0"}
-	for _, lineArray := range comments.Comments {
+	for name, lineArray := range comments.Comments {
+		if strings.HasPrefix(name, "3.") {
+			open.Version = name
+		}
 		for _, lines := range lineArray {
 			err := parseOpenLines(lines, open)
 			if err != nil {
@@ -81,7 +85,7 @@ func BuildOpenApi(comments in.SwagifyComment) OpenApi {
 }
 
 func parseOpenLines(lines []string, open *OpenApi) error {
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z/.]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
 	info := Info{}
 	contact := Contact{}
 	license := License{}
@@ -99,6 +103,13 @@ func parseOpenLines(lines []string, open *OpenApi) error {
 			info.Title = value
 		case "info.description":
 			info.Description = value
+		case "info.description_file", "description_file":
+			description, err := in.FileDescription(value)
+			if err != nil {
+				perr.AddError(fmt.Sprintf("[Warning] @@openapi: unable to read description_file: %s", err))
+				continue
+			}
+			info.Description = description
 		case "info.termOfService":
 			info.TermsOfService = value
 		case "info.version":
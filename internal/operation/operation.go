@@ -3,6 +3,7 @@ package operation
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	in "github.com/blackflagsoftware/go-swagify/internal"
@@ -22,11 +23,14 @@ type (
 	Operation struct {
 		Summary     string             `json:"summary,omitempty" yaml:"summary,omitempty"`
 		Description string             `json:"description,omitempty" yaml:"description,omitempty"`
+		OperationId string             `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+		Deprecated  bool               `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
 		Tags        []string           `json:"tags,omitempty" yaml:"tags,omitempty"`
 		Parameters  []par.ParameterRef `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 		RequestBody req.ReqSchema      `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
 		// Servers     []srv.Server    `json:"servers" yaml:"servers"`
 		Response map[string]res.Response `json:"responses,omitempty" yaml:"responses,omitempty"`
+		Pos      string                  `json:"-" yaml:"-"` // file:line of the func the operation is above, for the messages
 	}
 )
 
@@ -35,14 +39,19 @@ type (
 
 @@operation: <path url>
 @@method: get|put|post|delete|options|head|patch|trace
-@@summary: (optional)
-@@description: (optional)
+@@summary: (optional) the first sentence of the func's doc comment if omitted
+@@description: (optional) the rest of the func's doc comment if omitted
+@@description_file: (optional) file with the description (i.e. markdown), relative to the source file
+@@operationId: (optional) the func's name if omitted
 @@parameters.ref: (optional) semicolon(;) list of ref parameter names
 @@resp_name: (required) 200, 300, 4xx, etc
 @@resp_ref: (required) name of the response reference
-... @@resp_name, resp_ref can repeat
+... @@resp_name, resp_ref can repeat, anywhere in the block, each @@resp_ref is of the @@resp_name before it
+
+when the block is directly above a func (in its doc comment) the func fills in what is omitted, see in.Source.OperationFuncs
+the @@routes are added to them, see parseRoute
 */
-func BuildOperations(comments in.SwagifyComment) map[string]OperationBuild {
+func BuildOperations(comments, routes in.SwagifyComment, funcs map[string]in.Func, parameters map[string]par.Parameter) map[string]OperationBuild {
 	operations := make(map[string]OperationBuild)
 	for name, lineArray := range comments.Comments {
 		operationBuild := OperationBuild{Operations: make(map[string]Operation)}
@@ -55,16 +64,115 @@ func BuildOperations(comments in.SwagifyComment) map[string]OperationBuild {
 		}
 		operations[name] = operationBuild
 	}
+	// in order, the same route twice is always the same warning
+	routeNames := []string{}
+	for route := range routes.Comments {
+		routeNames = append(routeNames, route)
+	}
+	sort.Strings(routeNames)
+	for _, route := range routeNames {
+		for _, lines := range routes.Comments[route] {
+			path, method, operation, ok := parseRoute(route, lines, parameters)
+			if !ok {
+				continue
+			}
+			operationBuild, ok := operations[path]
+			if !ok {
+				operationBuild = OperationBuild{Operations: make(map[string]Operation)}
+				operations[path] = operationBuild
+			}
+			if _, ok := operationBuild.Operations[method]; ok {
+				perr.AddError(fmt.Sprintf("[Warning] @@route: %s %s is already an operation, the route is not used", method, path))
+				continue
+			}
+			operationBuild.Operations[method] = operation
+		}
+	}
+	for name, operationBuild := range operations {
+		for method, operation := range operationBuild.Operations {
+			if f, ok := funcs[in.OperationKey(name, method)]; ok {
+				operationBuild.Operations[method] = funcOperation(operation, f)
+			}
+		}
+	}
+	checkOperationIds(operations)
 	return operations
 }
 
+// fill in the operation from the func it's above
+func funcOperation(operation Operation, f in.Func) Operation {
+	summary, description := splitDoc(f.Doc)
+	if operation.Summary == "" {
+		operation.Summary = summary
+	}
+	if operation.Description == "" {
+		operation.Description = description
+	}
+	if operation.OperationId == "" {
+		operation.OperationId = f.Name
+	}
+	for _, paragraph := range strings.Split(f.Doc, "\n\n") {
+		if strings.HasPrefix(paragraph, "Deprecated: ") {
+			operation.Deprecated = true
+		}
+	}
+	operation.Pos = f.Pos
+	return operation
+}
+
+// the first sentence of the doc comment and the rest of it, i.e. "GetUser returns the user. It is ..."
+func splitDoc(doc string) (summary, description string) {
+	doc = strings.TrimSpace(doc)
+	end := len(doc)
+	if idx := strings.Index(doc, "\n\n"); idx > -1 {
+		end = idx
+	}
+	for i := 0; i < end-1; i++ {
+		if doc[i] == '.' && (doc[i+1] == ' ' || doc[i+1] == '\n') {
+			end = i + 1
+			break
+		}
+	}
+	summary = strings.Join(strings.Fields(doc[:end]), " ")
+	return summary, strings.TrimSpace(doc[end:])
+}
+
+// an operationId is unique in the spec
+func checkOperationIds(operations map[string]OperationBuild) {
+	found := make(map[string][]string)
+	for name, operationBuild := range operations {
+		for method, operation := range operationBuild.Operations {
+			if operation.OperationId == "" {
+				continue
+			}
+			where := method + " " + name
+			if operation.Pos != "" {
+				where += " (" + operation.Pos + ")"
+			}
+			found[operation.OperationId] = append(found[operation.OperationId], where)
+		}
+	}
+	for operationId, wheres := range found {
+		if len(wheres) > 1 {
+			sort.Strings(wheres)
+			perr.AddError(fmt.Sprintf("[Warning] @@operation: operationId %s is used by more than one operation: %s", operationId, strings.Join(wheres, ", ")))
+		}
+	}
+}
+
+/*
+the lines can be in any order, the resp_* lines are handed to responses (in their order, a resp_ref is of
+the resp_name before it), an option other than those is used once, a repeated one is warned about and the
+last one wins
+*/
 func parseOperationLines(lines []string, operationBuild OperationBuild) error {
 	operation := Operation{}
 	// go through each line and do logic on
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
 	method := ""
-lines_loop:
-	for i, line := range lines {
+	responseLines := []string{}
+	used := make(map[string]struct{})
+	for _, line := range lines {
 		matches := reg.FindStringSubmatch(line)
 		nameIdx := reg.SubexpIndex("name")
 		valueIdx := reg.SubexpIndex("value")
@@ -72,14 +180,33 @@ lines_loop:
 			perr.AddError(fmt.Sprintf("[Warning] @@operation: bad format of line: %s", line))
 			continue
 		}
+		name := matches[nameIdx]
 		value := strings.TrimSpace(matches[valueIdx])
-		switch matches[nameIdx] {
+		if name == "resp_name" || name == "resp_ref" {
+			responseLines = append(responseLines, line)
+			continue
+		}
+		option := strings.TrimSuffix(name, "_file")
+		if _, ok := used[option]; ok {
+			perr.AddError(fmt.Sprintf("[Warning] @@operation: %s is used more than once, the last one is used: %s", option, line))
+		}
+		used[option] = struct{}{}
+		switch name {
 		case "method":
 			method = value
 		case "summary":
 			operation.Summary = value
 		case "description":
 			operation.Description = value
+		case "description_file":
+			description, err := in.FileDescription(value)
+			if err != nil {
+				perr.AddError(fmt.Sprintf("[Warning] @@operation: unable to read description_file: %s", err))
+				continue
+			}
+			operation.Description = description
+		case "operationId":
+			operation.OperationId = value
 		case "tags":
 			operation.Tags = strings.Split(value, ";")
 		case "parameters.ref":
@@ -91,14 +218,14 @@ lines_loop:
 			operation.Parameters = parameters
 		case "req_ref":
 			operation.RequestBody = req.ReqSchema{Ref: fmt.Sprintf("#/components/requestBodies/%s", value)}
-		case "resp_name":
-			// hand off all the rest of the lines to responses
-			operation.Response = res.ParseOperationResponseLines(lines[i:])
-			break lines_loop
 		default:
+			delete(used, option)
 			perr.AddError(fmt.Sprintf("[Warning] @@operation: invalid name option: %s", line))
 		}
 	}
+	if len(responseLines) > 0 {
+		operation.Response = res.ParseOperationResponseLines(responseLines)
+	}
 	if method == "" {
 		perr.AddError(fmt.Sprintf("[Error] @@operation: no method specified"))
 		return nil
@@ -0,0 +1,92 @@
+package operation
+
+import (
+	"testing"
+
+	in "github.com/blackflagsoftware/go-swagify/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildOperations_func(t *testing.T) {
+	comments := in.SwagifyComment{Comments: map[string][][]string{
+		"/user/{id}": {
+			{"method: get", "resp_name: 200", "resp_ref: User"},
+			{"method: delete", "summary: Remove the user", "description: | code |\n|------|\n| 204  |", "operationId: removeUser"},
+		},
+	}}
+	funcs := map[string]in.Func{
+		in.OperationKey("/user/{id}", "get"):    {Name: "GetUser", Doc: "GetUser returns the user. It is\nby its id.\n\nDeprecated: use FindUser.", Pos: "user.go:10"},
+		in.OperationKey("/user/{id}", "delete"): {Name: "DeleteUser", Doc: "DeleteUser deletes the user.", Pos: "user.go:20"},
+	}
+	operations := BuildOperations(comments, in.SwagifyComment{}, funcs, nil)
+	get := operations["/user/{id}"].Operations["get"]
+	assert.Equal(t, "GetUser returns the user.", get.Summary)
+	assert.Equal(t, "It is\nby its id.\n\nDeprecated: use FindUser.", get.Description)
+	assert.Equal(t, "GetUser", get.OperationId)
+	assert.True(t, get.Deprecated)
+	assert.Equal(t, "user.go:10", get.Pos)
+	del := operations["/user/{id}"].Operations["delete"]
+	assert.Equal(t, "Remove the user", del.Summary)
+	assert.Equal(t, "| code |\n|------|\n| 204  |", del.Description)
+	assert.Equal(t, "removeUser", del.OperationId)
+	assert.False(t, del.Deprecated)
+}
+
+func TestBuildOperations_route(t *testing.T) {
+	comments := in.SwagifyComment{Comments: map[string][][]string{
+		"/user": {{"method: get", "summary: List the users"}},
+	}}
+	routes := in.SwagifyComment{Comments: map[string][][]string{
+		"GET /user":         {{"summary: Not used"}},
+		"POST /user":        {{}},
+		"DELETE /user/{id}": {{}},
+	}}
+	funcs := map[string]in.Func{
+		in.OperationKey("/user/{id}", "delete"): {Name: "DeleteUser", Doc: "DeleteUser deletes the user.", Pos: "user.go:20"},
+	}
+	operations := BuildOperations(comments, routes, funcs, nil)
+	// the @@operation is kept
+	assert.Equal(t, "List the users", operations["/user"].Operations["get"].Summary)
+	assert.Contains(t, operations["/user"].Operations, "post")
+	del := operations["/user/{id}"].Operations["delete"]
+	assert.Equal(t, "DeleteUser", del.OperationId)
+	assert.Equal(t, "DeleteUser deletes the user.", del.Summary)
+}
+
+func Test_parseOperationLines_order(t *testing.T) {
+	operationBuild := OperationBuild{Operations: make(map[string]Operation)}
+	parseOperationLines([]string{
+		"resp_name: 200",
+		"resp_ref: User",
+		"tags: User",
+		"method: get",
+		"resp_name: 404",
+		"req_ref: UserRequest",
+		"resp_ref: Error",
+		"summary: Get User",
+	}, operationBuild)
+	get := operationBuild.Operations["get"]
+	assert.Equal(t, "Get User", get.Summary)
+	assert.Equal(t, []string{"User"}, get.Tags)
+	assert.Equal(t, "#/components/requestBodies/UserRequest", get.RequestBody.Ref)
+	assert.Equal(t, "#/components/responses/User", get.Response["200"].Ref)
+	assert.Equal(t, "#/components/responses/Error", get.Response["404"].Ref)
+}
+
+func Test_splitDoc(t *testing.T) {
+	tests := []struct {
+		doc         string
+		summary     string
+		description string
+	}{
+		{"GetUser returns the user.", "GetUser returns the user.", ""},
+		{"GetUser returns\nthe user v1.2 by id", "GetUser returns the user v1.2 by id", ""},
+		{"GetUser returns the user\n\nThe rest.", "GetUser returns the user", "The rest."},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		summary, description := splitDoc(tt.doc)
+		assert.Equal(t, tt.summary, summary, tt.doc)
+		assert.Equal(t, tt.description, description, tt.doc)
+	}
+}
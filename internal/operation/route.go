@@ -0,0 +1,141 @@
+package operation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	par "github.com/blackflagsoftware/go-swagify/internal/parameter"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+	req "github.com/blackflagsoftware/go-swagify/internal/requestBody"
+	res "github.com/blackflagsoftware/go-swagify/internal/response"
+	sch "github.com/blackflagsoftware/go-swagify/internal/schema"
+)
+
+var (
+	// 200, 4XX, default
+	statusReg = regexp.MustCompile(`^([1-5](\d\d|XX)|default)$`)
+	// {id} of /user/{id}
+	pathParamReg = regexp.MustCompile(`{([^{}]+)}`)
+)
+
+/*
+	go-swagify
+
+@@route: <method> <path url> <option>=<value> ...
+- tags: semicolon(;) list of tags
+- req: name of the request body reference
+- params: semicolon(;) list of ref parameter names
+- id: the operationId
+- summary: in double quotes if it has spaces, i.e. summary="Get User"
+- <status code>: name of the response reference, i.e. 200=UserResponseRef 404=Error
+the same as an @@operation: <path url> with @@method: <method> (and the path is added if there's no @@path for it),
+any of the @@operation lines can follow it, they win over the options (i.e. @@tags over tags=), i.e.
+
+	@@route: GET /user/{id} tags=User 200=UserResponseRef 404=Error
+	@@description: Get a single User record by identifier
+
+each {name} of the path is a path parameter (type string) unless one of the params is
+*/
+func parseRoute(route string, lines []string, parameters map[string]par.Parameter) (path, method string, operation Operation, ok bool) {
+	fields := routeFields(route)
+	if len(fields) < 2 {
+		perr.AddError(fmt.Sprintf("[Warning] @@route: expected <method> <path url>, got: %s", route))
+		return
+	}
+	method, path = strings.ToLower(fields[0]), fields[1]
+	operationBuild := OperationBuild{Operations: make(map[string]Operation)}
+	parseOperationLines(append([]string{"method: " + method}, lines...), operationBuild)
+	operation, ok = operationBuild.Operations[method]
+	if !ok {
+		// the method is not valid, the warning is added by parseOperationLines
+		return
+	}
+	if operation.Response == nil {
+		operation.Response = make(map[string]res.Response)
+	}
+	for _, field := range fields[2:] {
+		split := strings.SplitN(field, "=", 2)
+		if len(split) != 2 || split[1] == "" {
+			perr.AddError(fmt.Sprintf("[Warning] @@route: %s: expected <option>=<value>, got: %s", route, field))
+			continue
+		}
+		option, value := split[0], split[1]
+		switch {
+		case option == "tags":
+			if len(operation.Tags) == 0 {
+				operation.Tags = strings.Split(value, ";")
+			}
+		case option == "req":
+			if operation.RequestBody.Ref == "" {
+				operation.RequestBody = req.ReqSchema{Ref: fmt.Sprintf("#/components/requestBodies/%s", value)}
+			}
+		case option == "params":
+			if len(operation.Parameters) > 0 {
+				continue
+			}
+			for _, name := range strings.Split(value, ";") {
+				operation.Parameters = append(operation.Parameters, par.ParameterRef{Ref: fmt.Sprintf("#/components/parameters/%s", name)})
+			}
+		case option == "id":
+			if operation.OperationId == "" {
+				operation.OperationId = value
+			}
+		case option == "summary":
+			if operation.Summary == "" {
+				operation.Summary = value
+			}
+		case statusReg.MatchString(option):
+			if _, ok := operation.Response[option]; !ok {
+				operation.Response[option] = res.Response{Ref: "#/components/responses/" + value}
+			}
+		default:
+			perr.AddError(fmt.Sprintf("[Warning] @@route: %s: invalid option: %s", route, option))
+		}
+	}
+	if len(operation.Response) == 0 {
+		operation.Response = nil
+	}
+	operation.Parameters = append(operation.Parameters, pathParameters(path, operation.Parameters, parameters)...)
+	return path, method, operation, true
+}
+
+// the path parameters of the path's {name}s, but the ones of the ref parameters
+func pathParameters(path string, refs []par.ParameterRef, parameters map[string]par.Parameter) (pathParams []par.ParameterRef) {
+	inRefs := make(map[string]struct{})
+	for _, ref := range refs {
+		if parameter, ok := parameters[strings.TrimPrefix(ref.Ref, "#/components/parameters/")]; ok && parameter.In == "path" {
+			inRefs[parameter.Name] = struct{}{}
+		}
+	}
+	for _, match := range pathParamReg.FindAllStringSubmatch(path, -1) {
+		if _, ok := inRefs[match[1]]; ok {
+			continue
+		}
+		pathParams = append(pathParams, par.ParameterRef{Name: match[1], In: "path", Required: true, Schema: &sch.SchemaProperty{Type: "string"}})
+	}
+	return
+}
+
+// the fields of the route, separated by spaces, a value in double quotes can have spaces, i.e. summary="Get User"
+func routeFields(route string) (fields []string) {
+	var field strings.Builder
+	quoted := false
+	for _, c := range route {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ' ' && !quoted:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(c)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return
+}
@@ -0,0 +1,101 @@
+package operation
+
+import (
+	"testing"
+
+	par "github.com/blackflagsoftware/go-swagify/internal/parameter"
+	req "github.com/blackflagsoftware/go-swagify/internal/requestBody"
+	res "github.com/blackflagsoftware/go-swagify/internal/response"
+	sch "github.com/blackflagsoftware/go-swagify/internal/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseRoute(t *testing.T) {
+	parameters := map[string]par.Parameter{"UserId": {Name: "id", In: "path"}}
+	idParam := par.ParameterRef{Name: "id", In: "path", Required: true, Schema: &sch.SchemaProperty{Type: "string"}}
+	tests := []struct {
+		name      string
+		route     string
+		lines     []string
+		path      string
+		method    string
+		operation Operation
+		ok        bool
+	}{
+		{
+			"full",
+			`GET /user/{id} tags=User;Admin req=UserRequest id=getUser summary="Get User" 200=UserResponseRef 404=Error`,
+			[]string{},
+			"/user/{id}",
+			"get",
+			Operation{
+				Summary:     "Get User",
+				OperationId: "getUser",
+				Tags:        []string{"User", "Admin"},
+				Parameters:  []par.ParameterRef{idParam},
+				RequestBody: req.ReqSchema{Ref: "#/components/requestBodies/UserRequest"},
+				Response: map[string]res.Response{
+					"200": {Ref: "#/components/responses/UserResponseRef"},
+					"404": {Ref: "#/components/responses/Error"},
+				},
+			},
+			true,
+		},
+		{
+			"the lines win",
+			"post /user summary=Short 201=User",
+			[]string{"summary: Long", "resp_name: 201", "resp_ref: Created"},
+			"/user",
+			"post",
+			Operation{
+				Summary:  "Long",
+				Response: map[string]res.Response{"201": {Ref: "#/components/responses/Created", Content: map[string]res.Content{}}},
+			},
+			true,
+		},
+		{
+			"the tags and parameters lines win",
+			"GET /user/{id} tags=User;Admin params=Paging",
+			[]string{"tags: Account", "parameters.ref: UserId"},
+			"/user/{id}",
+			"get",
+			Operation{
+				Tags:       []string{"Account"},
+				Parameters: []par.ParameterRef{{Ref: "#/components/parameters/UserId"}},
+			},
+			true,
+		},
+		{
+			"ref path parameter",
+			"DELETE /user/{id}/role/{role} params=UserId",
+			[]string{},
+			"/user/{id}/role/{role}",
+			"delete",
+			Operation{
+				Parameters: []par.ParameterRef{
+					{Ref: "#/components/parameters/UserId"},
+					{Name: "role", In: "path", Required: true, Schema: &sch.SchemaProperty{Type: "string"}},
+				},
+			},
+			true,
+		},
+		{"no path", "GET", []string{}, "", "", Operation{}, false},
+		{"invalid method", "FETCH /user", []string{}, "/user", "fetch", Operation{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, method, operation, ok := parseRoute(tt.route, tt.lines, parameters)
+			assert.Equal(t, tt.ok, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tt.path, path)
+			assert.Equal(t, tt.method, method)
+			assert.Equal(t, tt.operation, operation)
+		})
+	}
+}
+
+func Test_routeFields(t *testing.T) {
+	assert.Equal(t, []string{"GET", "/user", "summary=Get User", "200=User"}, routeFields(`GET  /user summary="Get User" 200=User`))
+}
@@ -20,7 +20,12 @@ type (
 	}
 
 	ParameterRef struct {
-		Ref string `json:"$ref" yaml:"$ref"`
+		Ref string `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+		// an inline parameter, without a Ref, i.e. the path parameters of a @@route
+		Name     string              `json:"name,omitempty" yaml:"name,omitempty"`
+		In       string              `json:"in,omitempty" yaml:"in,omitempty"`
+		Required bool                `json:"required,omitempty" yaml:"required,omitempty"`
+		Schema   *sch.SchemaProperty `json:"schema,omitempty" yaml:"schema,omitempty"`
 	}
 )
 
@@ -35,13 +40,14 @@ go-swagify
 @@schema_type: string
 @@schema_description: string
 @@schema_example: interface{}
+@@schema_enum: (optional) semicolon(;) list of values, the constants of a named type used in @@schema_type are used if omitted
 */
 
-func BuildParameters(comments in.SwagifyComment) map[string]Parameter {
+func BuildParameters(comments in.SwagifyComment, enums in.Enums) map[string]Parameter {
 	parameter := make(map[string]Parameter)
 	for name, lineArray := range comments.Comments {
 		for _, lines := range lineArray {
-			Parameter, err := parseParameterLines(lines)
+			Parameter, err := parseParameterLines(lines, enums)
 			if err != nil {
 				// will never be not nil
 				continue
@@ -52,11 +58,11 @@ func BuildParameters(comments in.SwagifyComment) map[string]Parameter {
 	return parameter
 }
 
-func parseParameterLines(lines []string) (Parameter, error) {
+func parseParameterLines(lines []string, enums in.Enums) (Parameter, error) {
 	var schemaProperty *sch.SchemaProperty
 	Parameter := Parameter{}
 	// go through each line and do logic on
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z_]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z_]+): *?(?P<value>.+)")
 	lastName := ""
 	for _, line := range lines {
 		matches := reg.FindStringSubmatch(line)
@@ -85,13 +91,23 @@ func parseParameterLines(lines []string) (Parameter, error) {
 			}
 		case "schema_type":
 			schemaProperty.Type = value
+			// a named type with constants, i.e. OrderStatus
+			if values, ok := enums.Lookup(value); ok {
+				schemaProperty.Type = sch.EnumType(values)
+				if schemaProperty.Enum == nil {
+					schemaProperty.Enum = values
+				}
+			}
 		case "schema_description":
 			schemaProperty.Description = value
 		case "schema_example":
 			// TODO: check for type and cast if appropriate, probably do this in schema.go
 			schemaProperty.ExampleStr = value
 		case "schema_enum":
-			schemaProperty.Enum = strings.Split(value, ";")
+			schemaProperty.Enum = []interface{}{}
+			for _, v := range strings.Split(value, ";") {
+				schemaProperty.Enum = append(schemaProperty.Enum, v)
+			}
 		default:
 			perr.AddError(fmt.Sprintf("[Warning] @@parameter: invalid name option: %s", line))
 		}
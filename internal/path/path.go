@@ -34,6 +34,7 @@ type (
 @@path: <path url>
 @@summary: (optional)
 @@description: (optional)
+@@description_file: (optional) file with the description (i.e. markdown), relative to the source file
 @@parameters.ref: (optional) semicolon(;) list of ref parameter names
 */
 func BuildPaths(comments in.SwagifyComment, operationBuilds map[string]opr.OperationBuild) map[string]Path {
@@ -54,12 +55,20 @@ func BuildPaths(comments in.SwagifyComment, operationBuilds map[string]opr.Opera
 			paths[name] = path
 		}
 	}
+	// the operations (i.e. @@route) of a path without a @@path
+	for name, operationBuild := range operationBuilds {
+		if _, ok := paths[name]; !ok {
+			path := Path{}
+			linkOperations(&path, operationBuild)
+			paths[name] = path
+		}
+	}
 	return paths
 }
 
 func parsePathLines(lines []string, path *Path) error {
 	// go through each line and do logic on
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z/.]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
 	for _, line := range lines {
 		matches := reg.FindStringSubmatch(line)
 		nameIdx := reg.SubexpIndex("name")
@@ -74,6 +83,13 @@ func parsePathLines(lines []string, path *Path) error {
 			path.Summary = value
 		case "description":
 			path.Description = value
+		case "description_file":
+			description, err := in.FileDescription(value)
+			if err != nil {
+				perr.AddError(fmt.Sprintf("[Warning] @@path: unable to read description_file: %s", err))
+				continue
+			}
+			path.Description = description
 		case "parameters.ref":
 			split := strings.Split(value, ";")
 			parameters := []par.ParameterRef{}
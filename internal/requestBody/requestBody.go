@@ -7,6 +7,7 @@ import (
 
 	in "github.com/blackflagsoftware/go-swagify/internal"
 	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+	sch "github.com/blackflagsoftware/go-swagify/internal/schema"
 )
 
 type (
@@ -19,6 +20,7 @@ type (
 
 	Content struct {
 		ReqSchema `json:"schema" yaml:"schema"`
+		Example   interface{} `json:"example,omitempty" yaml:"example,omitempty"`
 	}
 
 	ReqSchema struct {
@@ -35,13 +37,14 @@ type (
 @@required: (optional) true/false
 @@content_name: (not required if @@ref is used, else optional) application/json, etc
 @@content_ref: (not required if @@ref is used, else optional) schema reference
+@@example_file: (optional) file with the content's example, relative to the source file, a json file is parsed
 */
-func BuildRequestBody(comments in.SwagifyComment) map[string]RequestBody {
+func BuildRequestBody(comments in.SwagifyComment, refName sch.RefName) map[string]RequestBody {
 	requestBodies := make(map[string]RequestBody)
 	for name, lineArray := range comments.Comments {
 		for _, lines := range lineArray {
 			requestBody := &RequestBody{Content: make(map[string]Content)}
-			parseRequestBodyLines(lines, requestBody)
+			parseRequestBodyLines(lines, requestBody, refName)
 			blankOutRef(requestBody)
 			requestBodies[name] = *requestBody
 		}
@@ -50,9 +53,9 @@ func BuildRequestBody(comments in.SwagifyComment) map[string]RequestBody {
 }
 
 // called by the comments
-func parseRequestBodyLines(lines []string, requestBody *RequestBody) {
+func parseRequestBodyLines(lines []string, requestBody *RequestBody, refName sch.RefName) {
 	content := Content{}
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
 	currentContentName := ""
 	for _, line := range lines {
 		matches := reg.FindStringSubmatch(line)
@@ -81,7 +84,18 @@ func parseRequestBodyLines(lines []string, requestBody *RequestBody) {
 			}
 			currentContentName = value
 		case "content_ref":
-			content.Ref = "#/components/schemas/" + value
+			content.Ref = "#/components/schemas/" + refName(value)
+		case "example_file":
+			if currentContentName == "" {
+				perr.AddError(fmt.Sprintf("[Warning] @@requestBody: example_file without a content_name: %s", value))
+				continue
+			}
+			example, err := in.FileExample(value)
+			if err != nil {
+				perr.AddError(fmt.Sprintf("[Warning] @@requestBody: unable to read example_file: %s", err))
+				continue
+			}
+			content.Example = example
 		}
 	}
 	if currentContentName != "" {
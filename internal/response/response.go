@@ -7,6 +7,7 @@ import (
 
 	in "github.com/blackflagsoftware/go-swagify/internal"
 	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+	sch "github.com/blackflagsoftware/go-swagify/internal/schema"
 )
 
 type (
@@ -18,6 +19,7 @@ type (
 
 	Content struct {
 		RefSchema `json:"schema" yaml:"schema"`
+		Example   interface{} `json:"example,omitempty" yaml:"example,omitempty"`
 	}
 
 	RefSchema struct {
@@ -33,14 +35,15 @@ type (
 @@desc: (required, if @@ref not used)
 @@content_name: (not required if @@ref is used, else optional) application/json, etc
 @@content_ref: (not required if @@ref is used, else optional) schema reference
+@@example_file: (optional) file with the content's example, relative to the source file, a json file is parsed
 ... can repeat @@content_*
 */
-func BuildResponse(comments in.SwagifyComment) map[string]Response {
+func BuildResponse(comments in.SwagifyComment, refName sch.RefName) map[string]Response {
 	responses := make(map[string]Response)
 	for name, lineArray := range comments.Comments {
 		for _, lines := range lineArray {
 			response := &Response{Content: make(map[string]Content)}
-			parseResponseLines(lines, response)
+			parseResponseLines(lines, response, refName)
 			blankOutRef(response)
 			responses[name] = *response
 		}
@@ -48,11 +51,13 @@ func BuildResponse(comments in.SwagifyComment) map[string]Response {
 	return responses
 }
 
-// called by operation
+/*
+called by operation with its resp_* lines, in the order they are in the block, each @@resp_ref is
+of the @@resp_name before it
+*/
 func ParseOperationResponseLines(lines []string) map[string]Response {
 	responses := make(map[string]Response)
-	response := &Response{Content: make(map[string]Content)}
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
 	currentResponseName := ""
 	for _, line := range lines {
 		matches := reg.FindStringSubmatch(line)
@@ -65,27 +70,32 @@ func ParseOperationResponseLines(lines []string) map[string]Response {
 		value := strings.TrimSpace(matches[valueIdx])
 		switch matches[nameIdx] {
 		case "resp_name":
-			if currentResponseName != value {
-				if currentResponseName != "" {
-					responses[currentResponseName] = *response
-				}
-				response = &Response{Content: make(map[string]Content)}
+			if _, ok := responses[value]; ok && currentResponseName != value {
+				perr.AddError(fmt.Sprintf("[Warning] @@responses: resp_name: %s is used more than once", value))
+			}
+			if _, ok := responses[value]; !ok {
+				responses[value] = Response{Content: make(map[string]Content)}
 			}
 			currentResponseName = value
 		case "resp_ref":
+			if currentResponseName == "" {
+				perr.AddError(fmt.Sprintf("[Warning] @@responses: resp_ref: %s has no resp_name before it", value))
+				continue
+			}
+			response := responses[currentResponseName]
 			response.Ref = "#/components/responses/" + value
+			responses[currentResponseName] = response
+		default:
+			perr.AddError(fmt.Sprintf("[Warning] @@responses: invalid name option: %s", line))
 		}
 	}
-	if currentResponseName != "" {
-		responses[currentResponseName] = *response
-	}
 	return responses
 }
 
 // called by the comments
-func parseResponseLines(lines []string, response *Response) {
+func parseResponseLines(lines []string, response *Response, refName sch.RefName) {
 	content := Content{}
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
 	currentContentName := ""
 	for _, line := range lines {
 		matches := reg.FindStringSubmatch(line)
@@ -110,7 +120,18 @@ func parseResponseLines(lines []string, response *Response) {
 			}
 			currentContentName = value
 		case "content_ref":
-			content.Ref = "#/components/schemas/" + value
+			content.Ref = "#/components/schemas/" + refName(value)
+		case "example_file":
+			if currentContentName == "" {
+				perr.AddError(fmt.Sprintf("[Warning] @@response: example_file without a content_name: %s", value))
+				continue
+			}
+			example, err := in.FileExample(value)
+			if err != nil {
+				perr.AddError(fmt.Sprintf("[Warning] @@response: unable to read example_file: %s", err))
+				continue
+			}
+			content.Example = example
 		}
 	}
 	if currentContentName != "" {
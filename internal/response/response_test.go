@@ -1,12 +1,17 @@
 package response
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	in "github.com/blackflagsoftware/go-swagify/internal"
 )
 
+// the references as they are, there are no marked structs
+func asIs(ref string) string { return ref }
+
 func TestBuildResponse(t *testing.T) {
 	type args struct {
 		comments in.SwagifyComment
@@ -25,7 +30,7 @@ func TestBuildResponse(t *testing.T) {
 					"content_ref: response_1",
 				},
 			}}}},
-			map[string]Response{"200": {Description: "This is my description", Content: map[string]Content{"application/json": {RefSchema{Ref: "#/components/responses/response_1"}}}}},
+			map[string]Response{"200": {Description: "This is my description", Content: map[string]Content{"application/json": {RefSchema: RefSchema{Ref: "#/components/responses/response_1"}}}}},
 		},
 		{
 			"successful: one response (200) ref",
@@ -50,12 +55,12 @@ func TestBuildResponse(t *testing.T) {
 					"content_ref: response_2",
 				},
 			}}}},
-			map[string]Response{"200": {Description: "This is my description", Content: map[string]Content{"application/json": {RefSchema{Ref: "#/components/responses/response_1"}}, "application/text": {RefSchema{Ref: "#/components/responses/response_2"}}}}},
+			map[string]Response{"200": {Description: "This is my description", Content: map[string]Content{"application/json": {RefSchema: RefSchema{Ref: "#/components/responses/response_1"}}, "application/text": {RefSchema: RefSchema{Ref: "#/components/responses/response_2"}}}}},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := BuildResponse(tt.args.comments); !reflect.DeepEqual(got, tt.want) {
+			if got := BuildResponse(tt.args.comments, asIs); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("BuildResponse() = %v, want %v", got, tt.want)
 			}
 		})
@@ -89,6 +94,18 @@ func TestParseOperationResponseLines(t *testing.T) {
 			}},
 			map[string]Response{"400": {Ref: "#/components/responses/SomeErrorResponse", Content: map[string]Content{}}, "500": {Ref: "#/components/responses/SomeServerErrorResponse", Content: map[string]Content{}}},
 		},
+		{
+			"resp_ref without resp_name, resp_name again",
+			args{[]string{
+				"resp_ref: Lost",
+				"resp_name: 400",
+				"resp_name: 500",
+				"resp_ref: SomeServerErrorResponse",
+				"resp_name: 400",
+				"resp_ref: SomeErrorResponse",
+			}},
+			map[string]Response{"400": {Ref: "#/components/responses/SomeErrorResponse", Content: map[string]Content{}}, "500": {Ref: "#/components/responses/SomeServerErrorResponse", Content: map[string]Content{}}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -98,3 +115,31 @@ func TestParseOperationResponseLines(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildResponse_exampleFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "user.json")
+	if err := os.WriteFile(file, []byte(`{"id": 101}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	comments := in.SwagifyComment{Comments: map[string][][]string{"UserResponse": {{"desc: the user", "content_name: application/json", "content_ref: User", "example_file: " + file}}}}
+	got := BuildResponse(comments, asIs)
+	want := map[string]Response{"UserResponse": {Description: "the user", Content: map[string]Content{"application/json": {RefSchema: RefSchema{Ref: "#/components/schemas/User"}, Example: map[string]interface{}{"id": float64(101)}}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildResponse() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildResponse_refName(t *testing.T) {
+	comments := in.SwagifyComment{Comments: map[string][][]string{"UsersResponse": {{"desc: the users", "content_name: application/json", "content_ref: Page[User]"}}}}
+	refName := func(ref string) string {
+		if ref == "Page[User]" {
+			return "PageUser"
+		}
+		return ref
+	}
+	got := BuildResponse(comments, refName)
+	if ref := got["UsersResponse"].Content["application/json"].Ref; ref != "#/components/schemas/PageUser" {
+		t.Errorf("BuildResponse() content ref = %s, want #/components/schemas/PageUser", ref)
+	}
+}
@@ -0,0 +1,368 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/blackflagsoftware/go-swagify/config"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+)
+
+type (
+	// the source tree as parsed by ScanDir, each file is parsed once and everything is built from it
+	Source struct {
+		Blocks  []Block      // all of the comments (a run of line comments is one), the go-swagify ones are the annotation blocks
+		Structs []StructDecl // all of the struct declarations, marked or not
+		Consts  []ConstDecl  // the constants declared with a named type, in the order they are declared
+		Funcs   []Func       // the funcs with an @@operation block in their doc comment
+		fset    *token.FileSet
+	}
+
+	Block struct {
+		Text string         // raw text of the comment, with the /* */ or //
+		Pos  token.Position // of the start of the comment
+	}
+
+	StructDecl struct {
+		Struct  MyStruct // with all of its fields, tagged or not
+		PkgName string
+	}
+
+	ConstDecl struct {
+		Name  string
+		Type  string      // import path + name of the const's type, i.e. example.com/acme/user.Status
+		Value interface{} // string, bool, int64 or float64
+		Pos   string      // file:line of the const
+	}
+)
+
+/*
+parse every .go file under the directory (and its sub directories) once and keep what the
+Build* functions need from them, each directory is loaded as a package and type checked
+(go/types) once, the packages are listed by the go tool from the directory's module, see listPackages
+
+the files that are scanned are filtered, see skipFile, the directories are scanned by -workers at once,
+a package after the ones it imports, their results are merged in the order the directories are found
+(a directory, then its sub directories) so the output is always the same
+*/
+func ScanDir(directory string) *Source {
+	fset := token.NewFileSet()
+	source := &Source{fset: fset}
+	checkGlobs()
+	dirs := goDirs(directory, "", nil)
+	list, err := listPackages(fset, directory, dirs)
+	if err != nil {
+		// each directory is type checked on its own, see scanGoDir
+		fmt.Println("Error in listing the packages: ", err)
+	}
+	exports := &syncImporter{importer: importer.ForCompiler(fset, "gc", nil).(types.ImporterFrom)}
+	scans := make([]dirScan, len(dirs))
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(dirs) {
+		workers = len(dirs)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				scans[i] = scanGoDir(fset, dirs[i], list, exports)
+			}
+		}()
+	}
+	// a package waits for the ones it imports, they are sent before it so there is always one to scan
+	for _, i := range scanOrder(dirs, list) {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	for i, scan := range scans {
+		for _, message := range dirs[i].messages {
+			fmt.Println(message)
+		}
+		for _, message := range scan.messages {
+			fmt.Println(message)
+		}
+		for _, warning := range scan.warnings {
+			perr.AddError(warning)
+		}
+		source.Blocks = append(source.Blocks, scan.source.Blocks...)
+		source.Structs = append(source.Structs, scan.source.Structs...)
+		source.Consts = append(source.Consts, scan.source.Consts...)
+		source.Funcs = append(source.Funcs, scan.source.Funcs...)
+	}
+	return source
+}
+
+type (
+	// a directory with its .go files
+	goDir struct {
+		directory string
+		files     []string
+		messages  []string // printed, i.e. the directory could not be read
+	}
+
+	// what is found in one directory, the messages and warnings are kept until the results are merged
+	dirScan struct {
+		source   *Source
+		messages []string // printed
+		warnings []string // perr
+	}
+)
+
+// the indexes of the directories, the listed packages' first (a package after the ones it imports), then the others
+func scanOrder(dirs []goDir, list *packageList) []int {
+	indexes := make(map[string]int)
+	for i, dir := range dirs {
+		if root := list.root(dir.directory); root != nil {
+			indexes[root.Dir] = i
+		}
+	}
+	order := []int{}
+	if list != nil {
+		for _, dir := range list.order {
+			order = append(order, indexes[dir])
+		}
+	}
+	for i, dir := range dirs {
+		if list.root(dir.directory) == nil {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// the directory and its sub directories, the directory first, with the files that are scanned, see skipFile
+func goDirs(directory, rel string, rules []ignoreRule) []goDir {
+	dir := goDir{directory: directory}
+	if config.GitIgnore {
+		// a copy, the sub directories' rules are only for them
+		rules = append(rules[:len(rules):len(rules)], gitIgnoreRules(directory, rel)...)
+	}
+	subDirs := []goDir{}
+	dirItems, err := os.ReadDir(directory)
+	if err != nil {
+		dir.messages = append(dir.messages, fmt.Sprint("Error reading diretory: ", directory))
+	}
+	for _, di := range dirItems {
+		file := path.Join(directory, di.Name())
+		relFile := path.Join(rel, di.Name())
+		if di.IsDir() {
+			if !skipDir(relFile, rules) {
+				subDirs = append(subDirs, goDirs(file, relFile, rules)...)
+			}
+			continue
+		}
+		skip, err := skipFile(directory, relFile, rules)
+		if err != nil {
+			dir.messages = append(dir.messages, fmt.Sprint("Error in reading build constraints of file: ", file))
+		}
+		if !skip {
+			dir.files = append(dir.files, file)
+		}
+	}
+	return append([]goDir{dir}, subDirs...)
+}
+
+/*
+the package of a listed directory is type checked with all of its files (the ones that are not scanned are
+only parsed for it), the packages of the others (i.e. not in a module, more than one package) with the ones
+that are scanned and the exports importer
+*/
+func scanGoDir(fset *token.FileSet, dir goDir, list *packageList, exports types.Importer) (scan dirScan) {
+	scan.source = &Source{fset: fset}
+	root := list.root(dir.directory)
+	if root != nil {
+		defer close(root.done)
+	}
+	parsedFiles := make(map[string]*ast.File)
+	pkgFiles := make(map[string][]*ast.File)
+	for _, file := range dir.files {
+		parsedFile, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			scan.messages = append(scan.messages, fmt.Sprint("Error in parsing file: ", file))
+			parsedFiles[path.Base(file)] = nil
+			continue
+		}
+		scan.source.scanComments(parsedFile)
+		scan.source.scanFuncs(parsedFile)
+		parsedFiles[path.Base(file)] = parsedFile
+		pkgFiles[parsedFile.Name.Name] = append(pkgFiles[parsedFile.Name.Name], parsedFile)
+	}
+	if root != nil {
+		checkFiles := []*ast.File{}
+		for _, name := range root.files() {
+			parsedFile, ok := parsedFiles[name]
+			if !ok {
+				// not scanned (i.e. -exclude) but it's the package's
+				var err error
+				if parsedFile, err = parser.ParseFile(fset, path.Join(dir.directory, name), nil, parser.ParseComments); err != nil {
+					continue
+				}
+			}
+			if parsedFile == nil {
+				// could not be parsed
+				continue
+			}
+			checkFiles = append(checkFiles, parsedFile)
+		}
+		var info *types.Info
+		var warning string
+		root.types, info, warning = checkPackage(fset, list.importer(root), root.ImportPath, root.Name, checkFiles)
+		if warning != "" {
+			scan.warnings = append(scan.warnings, warning)
+		}
+		for _, parsedFile := range pkgFiles[root.Name] {
+			scan.source.scanStructs(parsedFile, root.ImportPath, info)
+			scan.source.scanConsts(parsedFile, info)
+		}
+		return
+	}
+	// keep the output in a stable order when a directory holds more than one package
+	pkgNames := []string{}
+	for pkgName := range pkgFiles {
+		pkgNames = append(pkgNames, pkgName)
+	}
+	sort.Strings(pkgNames)
+	for _, pkgName := range pkgNames {
+		pkgPath := packagePath(dir.directory, pkgName)
+		_, info, warning := checkPackage(fset, exports, pkgPath, pkgName, pkgFiles[pkgName])
+		if warning != "" {
+			scan.warnings = append(scan.warnings, warning)
+		}
+		for _, parsedFile := range pkgFiles[pkgName] {
+			scan.source.scanStructs(parsedFile, pkgPath, info)
+			scan.source.scanConsts(parsedFile, info)
+		}
+	}
+	return
+}
+
+func (s *Source) scanComments(parsedFile *ast.File) {
+	for _, c := range parsedFile.Comments {
+		for _, block := range commentBlocks(c) {
+			s.Blocks = append(s.Blocks, Block{Text: blockText(block, s.fset), Pos: s.fset.Position(block[0].Pos())})
+		}
+	}
+}
+
+func (s *Source) scanFuncs(parsedFile *ast.File) {
+	for _, decl := range parsedFile.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Doc == nil {
+			continue
+		}
+		f := Func{Name: funcDecl.Name.Name, Doc: docText(funcDecl.Doc), Pos: s.position(funcDecl.Pos())}
+		for _, block := range commentBlocks(funcDecl.Doc) {
+			f.Operations = append(f.Operations, operationKeys(blockText(block, nil))...)
+		}
+		if len(f.Operations) > 0 {
+			s.Funcs = append(s.Funcs, f)
+		}
+	}
+}
+
+func (s *Source) scanStructs(parsedFile *ast.File, pkgPath string, info *types.Info) {
+	for _, myStruct := range inspectStructs(parsedFile, pkgPath, info, s.position) {
+		s.Structs = append(s.Structs, StructDecl{Struct: myStruct, PkgName: parsedFile.Name.Name})
+	}
+}
+
+func (s *Source) scanConsts(parsedFile *ast.File, info *types.Info) {
+	s.Consts = append(s.Consts, inspectConsts(parsedFile, info, s.position)...)
+}
+
+func (s *Source) position(pos token.Pos) string {
+	position := s.fset.Position(pos)
+	return fmt.Sprintf("%s:%d", position.Filename, position.Line)
+}
+
+/*
+the structs that are marked by the @@struct comments, with the fields that are used: the tagged and embedded
+ones, or all of them when the struct is @@auto, used by "schema", see internal/schema/schema.go
+*/
+func (s *Source) MarkedStructs(comments SwagifyComment) (myStructs []MyStruct) {
+	for _, decl := range s.Structs {
+		myStruct := decl.Struct
+		marked, alias, auto := markedStruct(comments, decl.PkgName, myStruct.Pkg, myStruct.Name)
+		if !marked {
+			continue
+		}
+		myStruct.Alias, myStruct.Auto = alias, auto
+		myStruct.Fields = nil
+		for _, field := range decl.Struct.Fields {
+			// embedded fields are kept with or without a tag, their fields may be promoted
+			if field.Tag == "" && !field.Embedded && !auto {
+				continue
+			}
+			myStruct.Fields = append(myStruct.Fields, field)
+		}
+		myStructs = append(myStructs, myStruct)
+	}
+	s.unmatchedMarkers(comments)
+	return
+}
+
+// a marker that is not of any struct is a typo, or a struct that isn't scanned, it's not in the schemas
+func (s *Source) unmatchedMarkers(comments SwagifyComment) {
+	markers := []string{}
+	for comment := range comments.Comments {
+		markers = append(markers, comment)
+	}
+	sort.Strings(markers)
+	for _, comment := range markers {
+		marker, _ := structMarker(comment)
+		matched := false
+		for _, decl := range s.Structs {
+			if markerMatch(marker, decl.PkgName, decl.Struct.Pkg, decl.Struct.Name) > -1 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			perr.AddError(fmt.Sprintf("[Warning] @@struct: %s is not a struct that is scanned", comment))
+		}
+	}
+}
+
+// the values of the named types' constants, in the order they are declared, once (i.e. LevelDefault = LevelInfo)
+func (s *Source) Enums() Enums {
+	enums := make(Enums)
+	seen := make(map[string]map[interface{}]struct{})
+	for _, c := range s.Consts {
+		if seen[c.Type] == nil {
+			seen[c.Type] = make(map[interface{}]struct{})
+		}
+		if _, ok := seen[c.Type][c.Value]; ok {
+			continue
+		}
+		seen[c.Type][c.Value] = struct{}{}
+		enums[c.Type] = append(enums[c.Type], c.Value)
+	}
+	return enums
+}
+
+// the funcs by the path and method of their operations, i.e. "/user get", see internal/operation
+func (s *Source) OperationFuncs() map[string]Func {
+	funcs := make(map[string]Func)
+	for _, f := range s.Funcs {
+		for _, key := range f.Operations {
+			funcs[key] = f
+		}
+	}
+	return funcs
+}
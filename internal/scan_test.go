@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blackflagsoftware/go-swagify/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanDir(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod": "module example.com/acme\n\ngo 1.18\n",
+		"order/order.go": `package order
+
+type Status string
+
+/* go-swagify
+@@struct: Order
+*/
+const (
+	StatusOpen Status = "open"
+)
+
+type Order struct {
+	Status Status ` + "`json:\"status\" sw:\"Order\"`" + `
+}
+`,
+		"order/item/item.go": `package item
+
+type Item struct {
+	Name string
+}
+`,
+	})
+	source := ScanDir(dir)
+	if assert.Len(t, source.Blocks, 1) {
+		assert.True(t, strings.HasSuffix(source.Blocks[0].Pos.String(), "order/order.go:5:1"), source.Blocks[0].Pos)
+	}
+	if assert.Len(t, source.Consts, 1) {
+		assert.Equal(t, ConstDecl{Name: "StatusOpen", Type: "example.com/acme/order.Status", Value: "open", Pos: source.Consts[0].Pos}, source.Consts[0])
+		assert.True(t, strings.HasSuffix(source.Consts[0].Pos, "order/order.go:9"), source.Consts[0].Pos)
+	}
+	// the directory, then its sub directories
+	if assert.Len(t, source.Structs, 2) {
+		assert.True(t, strings.HasSuffix(source.Structs[0].Struct.Pos, "order/order.go:12"), source.Structs[0].Struct.Pos)
+		assert.Equal(t, "Item", source.Structs[1].Struct.Name)
+		assert.Equal(t, "item", source.Structs[1].PkgName)
+		assert.Len(t, source.Structs[1].Struct.Fields, 1)
+	}
+	component := ParseSwagifyBlocks(source.Blocks)
+	myStructs := source.MarkedStructs(component.Types["struct"])
+	if assert.Len(t, myStructs, 1) {
+		assert.Equal(t, "Order", myStructs[0].Name)
+	}
+}
+
+func TestScanDir_workers(t *testing.T) {
+	files := map[string]string{"go.mod": "module example.com/acme\n\ngo 1.18\n"}
+	for i := 0; i < 12; i++ {
+		pkg := fmt.Sprintf("pkg%02d", i)
+		files[pkg+"/"+pkg+".go"] = fmt.Sprintf(`package %s
+
+// A is a struct.
+/* go-swagify
+@@struct: A
+*/
+type A struct {
+	Name string
+}
+
+type Kind string
+
+const KindOne Kind = "one"
+`, pkg)
+	}
+	dir := writeFiles(t, files)
+	defer func(workers int) { config.Workers = workers }(config.Workers)
+	// everything but the type checked types, they are different for each scan
+	summary := func(source *Source) string {
+		lines := []string{}
+		for _, block := range source.Blocks {
+			lines = append(lines, block.Pos.String()+" "+block.Text)
+		}
+		for _, decl := range source.Structs {
+			lines = append(lines, decl.Struct.Pos+" "+decl.Struct.TypeName())
+		}
+		for _, c := range source.Consts {
+			lines = append(lines, fmt.Sprint(c))
+		}
+		return strings.Join(lines, "\n")
+	}
+	config.Workers = 1
+	want := summary(ScanDir(dir))
+	for _, workers := range []int{2, 8, 0} {
+		config.Workers = workers
+		assert.Equal(t, want, summary(ScanDir(dir)), "workers: %d", workers)
+	}
+}
+
+func TestScanDir_imports(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod": "module example.com/acme\n\ngo 1.18\n",
+		"a/a.go": `package a
+
+import (
+	"net/http"
+
+	"example.com/acme/a/b"
+)
+
+type A struct {
+	B      b.B
+	Header http.Header
+}
+`,
+		"a/b/b.go": `package b
+
+type B struct {
+	X int
+}
+`,
+	})
+	// the packages are the ones of the scanned directory's module, not of the working directory's
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func(workers int) { config.Workers = workers }(config.Workers)
+	config.Workers = 2
+	source := ScanDir(dir)
+	if !assert.Len(t, source.Structs, 2) || !assert.Len(t, source.Structs[0].Struct.Fields, 2) {
+		return
+	}
+	a, b := source.Structs[0].Struct, source.Structs[1].Struct
+	// b is type checked once, a uses its types
+	assert.True(t, a.Fields[0].GoType == b.GoType, a.Fields[0].GoType)
+	assert.Len(t, StructFields(a.Fields[0].GoType), 1)
+	assert.Equal(t, "net/http.Header", types.TypeString(a.Fields[1].GoType, nil))
+}
+
+func TestListPackages(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod": "module example.com/acme\n\ngo 1.18\n",
+		"a/a.go": "package a\n\nimport \"net/http\"\n\ntype A struct {\n\tHeader http.Header\n}\n",
+	})
+	list, err := listPackages(token.NewFileSet(), dir, []goDir{{directory: filepath.Join(dir, "a"), files: []string{"a.go"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := list.root(filepath.Join(dir, "a"))
+	if !assert.NotNil(t, root) {
+		return
+	}
+	// the scanned package is type checked from source, it's not built, its imports are
+	assert.Equal(t, "", root.Export)
+	if assert.Contains(t, list.pkgs, "net/http") {
+		assert.NotEqual(t, "", list.pkgs["net/http"].Export)
+	}
+}
+
+func TestScanDir_filter(t *testing.T) {
+	file := func(pkg string) string {
+		return "package " + pkg + "\n\ntype Kind string\n\nconst KindOne Kind = \"one\"\n"
+	}
+	dir := writeFiles(t, map[string]string{
+		"go.mod":                   "module example.com/acme\n\ngo 1.18\n",
+		".gitignore":               "gen/\n",
+		"api/api.go":               file("api"),
+		"api/api_test.go":          file("api"),
+		"api/integration.go":       "//go:build integration\n\npackage api\n\nconst KindTwo Kind = \"two\"\n",
+		"api/mock/mock.go":         file("mock"),
+		"api/vendor/v/v.go":        file("v"),
+		"api/testdata/t.go":        file("t"),
+		"api/node_modules/n/n.go":  file("n"),
+		"gen/gen.go":               file("gen"),
+		"other/other.go":           file("other"),
+		"other/sub/.gitignore":     "*.go\n!keep.go\n",
+		"other/sub/skip.go":        "package sub\n\nconst KindTwo Kind = \"two\"\n",
+		"other/sub/keep.go":        file("sub"),
+		"other/sub/sub/deeper.go":  file("sub"),
+		"other/sub/sub/deeper.txt": "",
+		"other/legacy/legacy.go":   file("legacy"),
+		"other/legacy/nested/n.go": file("nested"),
+	})
+	defer func() {
+		config.Include, config.Exclude, config.GitIgnore, config.Tags = nil, nil, false, nil
+	}()
+	scanned := func() (files []string) {
+		for _, c := range ScanDir(dir).Consts {
+			file := strings.TrimPrefix(c.Pos, dir+"/")
+			files = append(files, file[:strings.Index(file, ":")])
+		}
+		return
+	}
+	assert.Equal(t, []string{"api/api.go", "api/mock/mock.go", "gen/gen.go", "other/other.go", "other/legacy/legacy.go", "other/legacy/nested/n.go", "other/sub/keep.go", "other/sub/skip.go", "other/sub/sub/deeper.go"}, scanned())
+	config.GitIgnore = true
+	config.Tags = []string{"integration"}
+	config.Exclude = []string{"**/mock", "other/legacy"}
+	assert.Equal(t, []string{"api/api.go", "api/integration.go", "other/other.go", "other/sub/keep.go"}, scanned())
+	config.Include = []string{"api/*.go"}
+	assert.Equal(t, []string{"api/api.go", "api/integration.go"}, scanned())
+}
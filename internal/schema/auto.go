@@ -0,0 +1,53 @@
+package schema
+
+import (
+	"strings"
+
+	in "github.com/blackflagsoftware/go-swagify/internal"
+	"github.com/fatih/structtag"
+)
+
+/*
+a struct marked with @@auto: true (or -auto) has all of its exported fields in the schema named after the
+struct, without an "sw" tag, the name is from the json tag and the description from the field's doc comment
+the "sw" tag is only needed to change that
+- sw:"-" => the field is not in any schema
+- sw:"*" => the field is required
+- sw:"UserRequest*;UserResponse" => the field is in these schemas instead
+*/
+func autoFields(myStruct in.MyStruct, fields []in.MyField) []in.MyField {
+	auto := []in.MyField{}
+	for _, f := range fields {
+		tags, err := structtag.Parse(f.Tag)
+		if err != nil {
+			auto = append(auto, f)
+			continue
+		}
+		sw, err := tags.Get("sw")
+		switch {
+		case err != nil:
+			tags.Set(&structtag.Tag{Key: "sw", Name: myStruct.Name})
+		case sw.Name == "-":
+			continue
+		case sw.Name == "*":
+			tags.Set(&structtag.Tag{Key: "sw", Name: myStruct.Name + "*"})
+		default:
+			auto = append(auto, f)
+			continue
+		}
+		f.Tag = strings.TrimSpace(tags.String())
+		auto = append(auto, f)
+	}
+	return auto
+}
+
+func autoStructs(myStructs []in.MyStruct) []in.MyStruct {
+	auto := make([]in.MyStruct, len(myStructs))
+	for i, m := range myStructs {
+		if m.Auto {
+			m.Fields = autoFields(m, m.Fields)
+		}
+		auto[i] = m
+	}
+	return auto
+}
@@ -0,0 +1,201 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blackflagsoftware/go-swagify/config"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+	"github.com/fatih/structtag"
+)
+
+// validator rules that only set the format
+var formatRules = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"http_url": "uri",
+	"uuid":     "uuid",
+	"uuid3":    "uuid",
+	"uuid4":    "uuid",
+	"uuid5":    "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+	"datetime": "date-time",
+}
+
+// validator rules that only set the pattern
+var patternRules = map[string]string{
+	"alpha":       "^[a-zA-Z]+$",
+	"alphanum":    "^[a-zA-Z0-9]+$",
+	"numeric":     "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+	"number":      "^[0-9]+$",
+	"hexadecimal": "^(0[xX])?[0-9a-fA-F]+$",
+	"e164":        "^\\+[1-9]?[0-9]{7,14}$",
+	"lowercase":   "^[^A-Z]*$",
+	"uppercase":   "^[^a-z]*$",
+}
+
+/*
+the rules of the validate tag (go-playground/validator) as constraints of the property, i.e.
+validate:"required,min=1,max=64,email,oneof=a b c"
+- min, max, len, gt, gte, lt, lte => minimum/maximum (numbers), minLength/maxLength (strings), minItems/maxItems (arrays)
+- oneof => enum
+- email, url, uuid, ... => format
+- alpha, alphanum, numeric, ... => pattern
+- dive => the rules after it are for the array's items
+the sw_min, sw_max, sw_pattern and sw_format tags take precedence, sw_min and sw_max follow the same rules as min and max
+returns true if the field is required
+*/
+func applyConstraints(schemaProperty *SchemaProperty, tags *structtag.Tags) (required bool) {
+	if validate, err := tags.Get(config.ValidateTag); err == nil {
+		required = applyRules(schemaProperty, strings.Split(validate.Value(), ","))
+	}
+	for _, sw := range []string{"sw_min", "sw_max", "sw_pattern", "sw_format"} {
+		if tag, err := tags.Get(sw); err == nil {
+			applyRule(schemaProperty, strings.TrimPrefix(sw, "sw_"), tag.Value())
+		}
+	}
+	return
+}
+
+func applyRules(schemaProperty *SchemaProperty, rules []string) (required bool) {
+	for i := 0; i < len(rules); i++ {
+		rule := strings.TrimSpace(rules[i])
+		if rule == "" || strings.Contains(rule, "|") {
+			// or'ed rules can't be described
+			continue
+		}
+		name, value := rule, ""
+		if idx := strings.Index(rule, "="); idx > -1 {
+			name, value = rule[:idx], rule[idx+1:]
+		}
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			if schemaProperty.Items != nil {
+				applyRules(schemaProperty.Items, rules[i+1:])
+			} else if schemaProperty.AdditionalProperties != nil {
+				applyRules(schemaProperty.AdditionalProperties, rules[i+1:])
+			}
+			return
+		case "keys":
+			// the rules of a map's keys, nothing to describe them with
+			for i < len(rules) && strings.TrimSpace(rules[i]) != "endkeys" {
+				i++
+			}
+		default:
+			applyRule(schemaProperty, name, value)
+		}
+	}
+	return
+}
+
+func applyRule(schemaProperty *SchemaProperty, name, value string) {
+	if format, ok := formatRules[name]; ok {
+		schemaProperty.Format = format
+		return
+	}
+	if pattern, ok := patternRules[name]; ok {
+		schemaProperty.Pattern = pattern
+		return
+	}
+	switch name {
+	case "min", "gte":
+		setMin(schemaProperty, value, false)
+	case "max", "lte":
+		setMax(schemaProperty, value, false)
+	case "gt":
+		setMin(schemaProperty, value, true)
+	case "lt":
+		setMax(schemaProperty, value, true)
+	case "len":
+		setMin(schemaProperty, value, false)
+		setMax(schemaProperty, value, false)
+	case "oneof":
+		schemaProperty.Enum = []interface{}{}
+		for _, v := range strings.Fields(value) {
+			schemaProperty.Enum = append(schemaProperty.Enum, enumConv(schemaProperty.Type, strings.Trim(v, "'")))
+		}
+	case "startswith":
+		schemaProperty.Pattern = "^" + regexp.QuoteMeta(value)
+	case "endswith":
+		schemaProperty.Pattern = regexp.QuoteMeta(value) + "$"
+	case "pattern":
+		schemaProperty.Pattern = value
+	case "format":
+		schemaProperty.Format = value
+	}
+}
+
+// a minimum, minLength or minItems depending on the type, exclusive means greater than
+func setMin(schemaProperty *SchemaProperty, value string, exclusive bool) {
+	switch schemaProperty.Type {
+	case "integer", "number":
+		if f, ok := parseFloat(value); ok {
+			schemaProperty.Minimum = &f
+			schemaProperty.ExclusiveMinimum = exclusive
+		}
+	case "string":
+		if i, ok := parseInt(value); ok {
+			if exclusive {
+				i++
+			}
+			schemaProperty.MinLength = &i
+		}
+	case "array":
+		if i, ok := parseInt(value); ok {
+			if exclusive {
+				i++
+			}
+			schemaProperty.MinItems = &i
+		}
+	}
+}
+
+// a maximum, maxLength or maxItems depending on the type, exclusive means less than
+func setMax(schemaProperty *SchemaProperty, value string, exclusive bool) {
+	switch schemaProperty.Type {
+	case "integer", "number":
+		if f, ok := parseFloat(value); ok {
+			schemaProperty.Maximum = &f
+			schemaProperty.ExclusiveMaximum = exclusive
+		}
+	case "string":
+		if i, ok := parseInt(value); ok {
+			if exclusive {
+				i--
+			}
+			schemaProperty.MaxLength = &i
+		}
+	case "array":
+		if i, ok := parseInt(value); ok {
+			if exclusive {
+				i--
+			}
+			schemaProperty.MaxItems = &i
+		}
+	}
+}
+
+func parseFloat(value string) (float64, bool) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		perr.AddError(fmt.Sprintf("[Warning] @@struct: unable to cast constraint: %s to float", value))
+		return 0, false
+	}
+	return f, true
+}
+
+func parseInt(value string) (int, bool) {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		perr.AddError(fmt.Sprintf("[Warning] @@struct: unable to cast constraint: %s to int", value))
+		return 0, false
+	}
+	return i, true
+}
@@ -0,0 +1,230 @@
+package schema
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/blackflagsoftware/go-swagify/config"
+	in "github.com/blackflagsoftware/go-swagify/internal"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+	"github.com/fatih/structtag"
+)
+
+type (
+	// a field of the struct after the embedded structs are flattened
+	promotedField struct {
+		index  int
+		field  in.MyField
+		name   string
+		depth  int
+		tagged bool
+	}
+)
+
+/*
+the fields of a marked struct the way encoding/json sees them, unexported fields and fields tagged "-" are
+left out and the fields of embedded structs are promoted (flattened) into the struct:
+- an embedded field with a name in its app output format tag is not flattened, it's a field of that name
+- the least nested field of a name wins, if there are more at that depth the one with a name in its tag wins
+- otherwise none of the fields of that name are used
+the promoted fields are in the schemas of the embedded field's "sw" tag, if it has one, otherwise in all the
+schemas of the struct's own fields, a promoted field's own "sw" tag takes precedence
+
+embedded fields rendered as allOf (sw_embed:"allOf" or -embedMode allOf) are returned in embeds, if the
+embedded struct is marked, otherwise they are flattened
+*/
+func (b *structBuild) structFields(myStruct in.MyStruct) (fields []in.MyField, embeds []in.MyField) {
+	promoted := []promotedField{}
+	var collect func(fields []in.MyField, depth int, sw string, visited map[string]struct{})
+	collect = func(fields []in.MyField, depth int, sw string, visited map[string]struct{}) {
+		for _, f := range fields {
+			tags, err := structtag.Parse(f.Tag)
+			if err != nil {
+				fmt.Println("parseTag", err)
+				continue
+			}
+			if omitField(tags) {
+				continue
+			}
+			_, tagged := appFormatName(tags)
+			if f.Embedded && !tagged {
+				if depth == 0 && embedMode(tags) == "allOf" {
+					if b.embedRef(f) != "" {
+						embeds = append(embeds, f)
+						continue
+					}
+					perr.AddError(fmt.Sprintf("[Warning] @@struct: %s embeds %s, which is not a marked struct, unable to use allOf, it will be flattened", myStruct.Name, f.Name))
+				}
+				if embedded, key := b.embeddedFields(f); embedded != nil {
+					if _, ok := visited[key]; ok {
+						continue
+					}
+					embeddedSw := sw
+					if swTag, err := tags.Get("sw"); err == nil {
+						embeddedSw = stripRequired(swTag.Name)
+					} else if depth == 0 {
+						embeddedSw = strings.Join(ownSchemaNames(myStruct), ";")
+					}
+					nextVisited := map[string]struct{}{key: {}}
+					for k := range visited {
+						nextVisited[k] = struct{}{}
+					}
+					collect(embedded, depth+1, embeddedSw, nextVisited)
+					continue
+				}
+				// not a struct, encoding/json uses it as a field named after its type
+			}
+			if !ast.IsExported(f.Name) {
+				// encoding/json ignores unexported fields, other than embedded structs (above)
+				continue
+			}
+			if depth > 0 {
+				if _, err := tags.Get("sw"); err != nil && sw != "" {
+					tags.Set(&structtag.Tag{Key: "sw", Name: sw})
+					f.Tag = tags.String()
+				}
+			}
+			promoted = append(promoted, promotedField{index: len(promoted), field: f, name: determineFieldName(f.Name, tags), depth: depth, tagged: tagged})
+		}
+	}
+	collect(myStruct.Fields, 0, "", map[string]struct{}{})
+	// apply the rules of which field of the same name wins
+	byName := make(map[string][]promotedField)
+	for _, p := range promoted {
+		byName[p.name] = append(byName[p.name], p)
+	}
+	for _, p := range promoted {
+		if dominant, ok := dominantField(byName[p.name]); ok && dominant.index == p.index {
+			fields = append(fields, p.field)
+		}
+	}
+	return
+}
+
+// which of the fields with the same name wins, false if none do
+func dominantField(promoted []promotedField) (promotedField, bool) {
+	depth := promoted[0].depth
+	for _, p := range promoted {
+		if p.depth < depth {
+			depth = p.depth
+		}
+	}
+	candidates := []promotedField{}
+	for _, p := range promoted {
+		if p.depth == depth {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) > 1 {
+		tagged := []promotedField{}
+		for _, c := range candidates {
+			if c.tagged {
+				tagged = append(tagged, c)
+			}
+		}
+		candidates = tagged
+	}
+	if len(candidates) != 1 {
+		return promotedField{}, false
+	}
+	return candidates[0], true
+}
+
+// the fields of the embedded struct and a key to identify its type, nil if it is not a struct
+func (b *structBuild) embeddedFields(field in.MyField) ([]in.MyField, string) {
+	if field.GoType != nil && field.GoType != types.Typ[types.Invalid] {
+		goType := field.GoType
+		if pointer, ok := goType.(*types.Pointer); ok {
+			goType = pointer.Elem()
+		}
+		fields := in.StructFields(goType)
+		if named, ok := goType.(*types.Named); ok {
+			fields = nameFields(b.typeStruct(named), fields)
+		}
+		return fields, goType.String()
+	}
+	// not type checked, the only fields known are from a marked struct
+	if myStruct, ok := b.structs[field.Name]; ok {
+		return myStruct.Fields, myStruct.TypeName()
+	}
+	return nil, ""
+}
+
+// the schema name of the embedded field's type, if it's a marked struct
+func (b *structBuild) embedRef(field in.MyField) string {
+	if field.GoType != nil && field.GoType != types.Typ[types.Invalid] {
+		goType := field.GoType
+		if pointer, ok := goType.(*types.Pointer); ok {
+			goType = pointer.Elem()
+		}
+		if named, ok := goType.(*types.Named); ok {
+			return b.refs[typeName(named)]
+		}
+		return ""
+	}
+	return b.refs[field.Name]
+}
+
+// add a reference (allOf) to the embedded field's schema to the schemas of its "sw" tag, if it has one,
+// otherwise to all the schemas of the struct's own fields
+func (b *structBuild) addEmbed(myStruct in.MyStruct, field in.MyField) {
+	schemaNames := ownSchemaNames(myStruct)
+	if tags, err := structtag.Parse(field.Tag); err == nil {
+		if swTag, err := tags.Get("sw"); err == nil {
+			schemaNames = strings.Split(stripRequired(swTag.Name), ";")
+		}
+	}
+	ref := SchemaProperty{Ref: "#/components/schemas/" + b.embedRef(field)}
+	for _, name := range schemaNames {
+		b.addSchema(name)
+		schema := b.schemas[name]
+		schema.AllOf = append(schema.AllOf, ref)
+		b.schemas[name] = schema
+	}
+}
+
+// the (sorted) names of the schemas of the struct's own fields, not of the embedded ones
+func ownSchemaNames(myStruct in.MyStruct) []string {
+	own := in.MyStruct{Name: myStruct.Name, Pkg: myStruct.Pkg}
+	for _, f := range myStruct.Fields {
+		if !f.Embedded {
+			own.Fields = append(own.Fields, f)
+		}
+	}
+	names := []string{}
+	for name := range structSchemaNames(own) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// i.e. ExampleRequest*;ExampleResponse => ExampleRequest;ExampleResponse
+func stripRequired(sw string) string {
+	return strings.ReplaceAll(sw, "*", "")
+}
+
+func embedMode(tags *structtag.Tags) string {
+	if swEmbed, err := tags.Get("sw_embed"); err == nil {
+		return swEmbed.Name
+	}
+	return config.EmbedMode
+}
+
+// the field is ignored by encoding/json, json:"-"
+func omitField(tags *structtag.Tags) bool {
+	tag, err := tags.Get(config.AppOutputFormat)
+	return err == nil && tag.Name == "-" && len(tag.Options) == 0
+}
+
+// the name in the field's app output format tag, if there is one
+func appFormatName(tags *structtag.Tags) (string, bool) {
+	tag, err := tags.Get(config.AppOutputFormat)
+	if err != nil || tag.Name == "" {
+		return "", false
+	}
+	return tag.Name, true
+}
@@ -0,0 +1,240 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"path"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/blackflagsoftware/go-swagify/config"
+	in "github.com/blackflagsoftware/go-swagify/internal"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+	"github.com/fatih/structtag"
+)
+
+// the schema name of a generic struct's instantiation, i.e. Page[User] => PageUser
+const defaultGenericName = `{{.Name}}{{.Args | join ""}}`
+
+/*
+a marked generic struct, i.e.
+
+	type Page[T any] struct {
+		Items []T    `json:"items" sw:"Page"`
+		Next  string `json:"next" sw:"Page"`
+	}
+
+is not a schema itself, each of its instantiations is, i.e. a field of type Page[User] or @@content_ref: Page[User]
+=> PageUser with the fields of Page's schema, see -genericName to change the name
+*/
+func (b *structBuild) instanceRef(named *types.Named) string {
+	args := []string{}
+	for i := 0; i < named.TypeArgs().Len(); i++ {
+		args = append(args, b.typeArgName(named.TypeArgs().At(i)))
+	}
+	generic := b.generics[typeName(named)]
+	name := instanceName(generic, args)
+	if b.instances == nil {
+		b.instances = make(map[string]struct{})
+	}
+	if _, ok := b.instances[name]; ok {
+		return name
+	}
+	// before its fields are built, they may reference it
+	b.instances[name] = struct{}{}
+	instance := in.MyStruct{Name: name, Pkg: b.structs[typeName(named)].Pkg}
+	fields := in.StructFields(named)
+	if generic := b.typeStruct(named); generic.Auto {
+		fields = autoFields(generic, fields)
+	}
+	for _, f := range nameFields(b.typeStruct(named), fields) {
+		if tag, ok := instanceTag(f.Tag, generic, name); ok {
+			f.Tag = tag
+			instance.Fields = append(instance.Fields, f)
+		}
+	}
+	b.buildStruct(instance)
+	return name
+}
+
+// a field of the generic struct's schema is a field of the instantiation's schema, false if it's not in it
+func instanceTag(tag, generic, name string) (string, bool) {
+	tags, err := structtag.Parse(tag)
+	if err != nil {
+		return tag, true
+	}
+	sw, err := tags.Get("sw")
+	if err != nil {
+		// i.e. an embedded field
+		return tag, true
+	}
+	schemaNames := []string{}
+	for _, schemaName := range strings.Split(sw.Name, ";") {
+		if schemaName == "" {
+			continue
+		}
+		if n, required := determineRequired(schemaName); n == generic {
+			if required {
+				schemaNames = append(schemaNames, name+"*")
+			} else {
+				schemaNames = append(schemaNames, name)
+			}
+		}
+	}
+	if len(schemaNames) == 0 {
+		return tag, false
+	}
+	tags.Set(&structtag.Tag{Key: "sw", Name: strings.Join(schemaNames, ";")})
+	return tags.String(), true
+}
+
+// the name of a type argument in the instantiation's name, i.e. User, String, UserList
+func (b *structBuild) typeArgName(goType types.Type) string {
+	switch t := goType.(type) {
+	case *types.Named:
+		if t.TypeArgs().Len() > 0 && b.isGeneric(t) {
+			return b.unqualifiedName(t, b.instanceRef(t))
+		}
+		if ref := b.refs[typeName(t)]; ref != "" {
+			return b.unqualifiedName(t, ref)
+		}
+		return exportedName(t.Obj().Name())
+	case *types.Basic:
+		return exportedName(t.Name())
+	case *types.Pointer:
+		return b.typeArgName(t.Elem())
+	case *types.Slice:
+		return b.typeArgName(t.Elem()) + "List"
+	case *types.Array:
+		return b.typeArgName(t.Elem()) + "List"
+	case *types.Map:
+		return "Map" + b.typeArgName(t.Key()) + b.typeArgName(t.Elem())
+	}
+	return "Any"
+}
+
+// the schema name without the package of -schemaNaming, the instantiation's name has it, i.e. billingPageUser
+func (b *structBuild) unqualifiedName(named *types.Named, name string) string {
+	if pkg := named.Obj().Pkg(); pkg != nil && b.structs[typeName(named)].Alias != name {
+		name = strings.TrimPrefix(name, qualifiedSchemaName(pkg.Name(), pkg.Path(), ""))
+	}
+	return exportedName(name)
+}
+
+// the type is a marked generic struct (or an instantiation of one)
+func (b *structBuild) isGeneric(named *types.Named) bool {
+	_, ok := b.generics[typeName(named)]
+	return ok
+}
+
+func instanceName(generic string, args []string) string {
+	format := config.GenericName
+	if format == "" {
+		format = defaultGenericName
+	}
+	t, err := template.New("genericName").Funcs(sprig.GenericFuncMap()).Parse(format)
+	if err != nil {
+		perr.AddError(fmt.Sprintf("[Warning] @@struct: invalid -genericName: %s", err))
+		t = template.Must(template.New("genericName").Funcs(sprig.GenericFuncMap()).Parse(defaultGenericName))
+	}
+	var name bytes.Buffer
+	if err := t.Execute(&name, struct {
+		Name string
+		Args []string
+	}{generic, args}); err != nil {
+		perr.AddError(fmt.Sprintf("[Warning] @@struct: unable to name %s%v: %s", generic, args, err))
+		return generic + strings.Join(args, "")
+	}
+	return name.String()
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// the schema name of a reference in the comments, see RefName
+func (b *structBuild) refName(ref string) string {
+	if !strings.Contains(ref, "[") {
+		return ref
+	}
+	expr, err := parser.ParseExpr(ref)
+	if err != nil {
+		perr.AddError(fmt.Sprintf("[Warning] @@struct: invalid reference: %s", ref))
+		return ref
+	}
+	named, ok := b.exprType(expr).(*types.Named)
+	if !ok || named.TypeArgs().Len() == 0 || !b.isGeneric(named) {
+		perr.AddError(fmt.Sprintf("[Warning] @@struct: %s is not an instantiation of a marked generic struct", ref))
+		return ref
+	}
+	return b.instanceRef(named)
+}
+
+// the type of a reference's source, the types known are the marked structs and the predeclared types
+func (b *structBuild) exprType(expr ast.Expr) types.Type {
+	switch t := expr.(type) {
+	case *ast.IndexExpr:
+		return b.instantiate(t.X, []ast.Expr{t.Index})
+	case *ast.IndexListExpr:
+		return b.instantiate(t.X, t.Indices)
+	case *ast.StarExpr:
+		if elem := b.exprType(t.X); elem != nil {
+			return types.NewPointer(elem)
+		}
+	case *ast.ArrayType:
+		if elem := b.exprType(t.Elt); elem != nil {
+			return types.NewSlice(elem)
+		}
+	case *ast.MapType:
+		key, value := b.exprType(t.Key), b.exprType(t.Value)
+		if key != nil && value != nil {
+			return types.NewMap(key, value)
+		}
+	case *ast.Ident:
+		if myStruct, ok := b.structs[t.Name]; ok {
+			return myStruct.GoType
+		}
+		if obj, ok := types.Universe.Lookup(t.Name).(*types.TypeName); ok {
+			return obj.Type()
+		}
+	case *ast.SelectorExpr:
+		// package name + struct name, i.e. user.User
+		for _, myStruct := range b.structs {
+			if myStruct.Name == t.Sel.Name && path.Base(myStruct.Pkg) == types.ExprString(t.X) {
+				return myStruct.GoType
+			}
+		}
+	}
+	return nil
+}
+
+func (b *structBuild) instantiate(genericExpr ast.Expr, argExprs []ast.Expr) types.Type {
+	generic := b.exprType(genericExpr)
+	if generic == nil {
+		return nil
+	}
+	args := []types.Type{}
+	for _, argExpr := range argExprs {
+		arg := b.exprType(argExpr)
+		if arg == nil {
+			return nil
+		}
+		args = append(args, arg)
+	}
+	instance, err := types.Instantiate(nil, generic, args, true)
+	if err != nil {
+		perr.AddError(fmt.Sprintf("[Warning] @@struct: unable to instantiate %s: %s", types.ExprString(genericExpr), err))
+		return nil
+	}
+	return instance
+}
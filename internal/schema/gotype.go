@@ -0,0 +1,266 @@
+package schema
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"strings"
+
+	in "github.com/blackflagsoftware/go-swagify/internal"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+	"github.com/fatih/structtag"
+)
+
+// build the property of a field from its type, goType is used when the field was type checked
+// otherwise the source text of the type is matched
+func (b *structBuild) typeProperty(fieldType string, goType types.Type) SchemaProperty {
+	expr, err := parser.ParseExpr(fieldType)
+	if err != nil {
+		expr = nil
+	}
+	return b.exprProperty(expr, goType)
+}
+
+/*
+expr is the source of the type, if known, and is walked alongside goType so the source can be
+used for any part of the type that could not be resolved
+- type mappings (-config, -typeMapping) => as mapped, before anything else
+- marked struct => $ref, a marked generic struct's instantiation => $ref to its schema, see generic.go
+- well known types, see wellknown.go
+- MarshalJSON, MarshalText => see marshaler.go
+- slice, array => array with "items"
+- map => object with "additionalProperties"
+- struct that is not marked => object with its fields, see inline.go
+all are recursive, i.e. map[string][]Tag
+*/
+func (b *structBuild) exprProperty(expr ast.Expr, goType types.Type) SchemaProperty {
+	if goType == nil || goType == types.Typ[types.Invalid] {
+		return b.sourceProperty(expr)
+	}
+	if named, ok := goType.(*types.Named); ok {
+		if schemaProperty, ok := mappedType(typeName(named)); ok {
+			return schemaProperty
+		}
+		if named.TypeArgs().Len() > 0 && b.isGeneric(named) {
+			return SchemaProperty{Ref: "#/components/schemas/" + b.instanceRef(named)}
+		}
+		if ref := b.refs[typeName(named)]; ref != "" {
+			return SchemaProperty{Ref: "#/components/schemas/" + ref}
+		}
+		if schemaProperty, ok := namedTypes[qualifiedName(named)]; ok {
+			return schemaProperty
+		}
+		if schemaProperty, ok := nullTypes[qualifiedName(named)]; ok {
+			return nullable(schemaProperty)
+		}
+		if named.TypeArgs().Len() == 1 && isNullGeneric(named) {
+			return nullable(b.exprProperty(nil, named.TypeArgs().At(0)))
+		}
+		values, isEnum := b.enums[typeName(named)]
+		if schemaProperty, ok := b.marshalerProperty(named); ok {
+			if isEnum && schemaProperty.Type == "string" && EnumType(values) == "string" {
+				schemaProperty.Enum = values
+			}
+			return schemaProperty
+		}
+		if isEnum {
+			schemaProperty := b.exprProperty(nil, named.Underlying())
+			schemaProperty.Enum = values
+			return schemaProperty
+		}
+		// only the source of the field's type is known, not its underlying type's
+		expr = nil
+	}
+	switch t := goType.Underlying().(type) {
+	case *types.Pointer:
+		return nullable(b.exprProperty(elemExpr(expr), t.Elem()))
+	case *types.Slice:
+		if isByte(t.Elem()) {
+			// encoding/json encodes []byte as a base64 string
+			return SchemaProperty{Type: "string", Format: "byte"}
+		}
+		items := b.exprProperty(elemExpr(expr), t.Elem())
+		return SchemaProperty{Type: "array", Items: &items}
+	case *types.Array:
+		items := b.exprProperty(elemExpr(expr), t.Elem())
+		return SchemaProperty{Type: "array", Items: &items}
+	case *types.Map:
+		value := b.exprProperty(elemExpr(expr), t.Elem())
+		return SchemaProperty{Type: "object", AdditionalProperties: &value}
+	case *types.Basic:
+		docType, format := basicType(t)
+		return SchemaProperty{Type: docType, Format: format}
+	case *types.Struct:
+		return b.inlineProperty(goType, t)
+	}
+	return SchemaProperty{Type: "string"}
+}
+
+// the type could not be type checked, do the best with the source
+func (b *structBuild) sourceProperty(expr ast.Expr) SchemaProperty {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return nullable(b.sourceProperty(t.X))
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && (ident.Name == "byte" || ident.Name == "uint8") {
+			return SchemaProperty{Type: "string", Format: "byte"}
+		}
+		items := b.sourceProperty(t.Elt)
+		return SchemaProperty{Type: "array", Items: &items}
+	case *ast.MapType:
+		value := b.sourceProperty(t.Value)
+		return SchemaProperty{Type: "object", AdditionalProperties: &value}
+	case *ast.Ident:
+		if ref := b.refs[t.Name]; ref != "" {
+			return SchemaProperty{Ref: "#/components/schemas/" + ref}
+		}
+		// try the source as a predeclared type, i.e. int64, bool
+		if obj, ok := types.Universe.Lookup(t.Name).(*types.TypeName); ok {
+			if basic, ok := obj.Type().(*types.Basic); ok {
+				docType, format := basicType(basic)
+				return SchemaProperty{Type: docType, Format: format}
+			}
+		}
+	case *ast.SelectorExpr:
+		if schemaProperty, ok := mappedType(types.ExprString(t)); ok {
+			return schemaProperty
+		}
+		if schemaProperty, ok := namedTypes[types.ExprString(t)]; ok {
+			return schemaProperty
+		}
+		if schemaProperty, ok := nullTypes[types.ExprString(t)]; ok {
+			return nullable(schemaProperty)
+		}
+		if ref := b.refs[t.Sel.Name]; ref != "" {
+			return SchemaProperty{Ref: "#/components/schemas/" + ref}
+		}
+	}
+	return SchemaProperty{Type: "string"}
+}
+
+func isByte(goType types.Type) bool {
+	basic, ok := goType.Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Uint8
+}
+
+func isNullGeneric(named *types.Named) bool {
+	if _, ok := nullGenerics[named.Obj().Name()]; ok {
+		return true
+	}
+	_, ok := nullGenerics[qualifiedName(named)]
+	return ok
+}
+
+// the source of the element type of a pointer, slice, array or map (value)
+func elemExpr(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return t.X
+	case *ast.ArrayType:
+		return t.Elt
+	case *ast.MapType:
+		return t.Value
+	}
+	return nil
+}
+
+func basicType(basic *types.Basic) (docType string, format string) {
+	switch basic.Kind() {
+	case types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16:
+		return "integer", "int32"
+	case types.Int, types.Int64, types.Uint, types.Uint32, types.Uint64, types.Uintptr:
+		return "integer", "int64"
+	case types.Float32:
+		return "number", "float"
+	case types.Float64:
+		return "number", "double"
+	case types.Bool:
+		return "boolean", ""
+	}
+	return "string", ""
+}
+
+// package name + type name, i.e. null.Int
+func qualifiedName(named *types.Named) string {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Name() + "." + obj.Name()
+}
+
+/*
+find the schema a marked struct is referenced by, when other fields use it as their type
+- the schema named after the struct (see naming.go), if one of its fields is in it
+- the only schema its fields are in
+the struct is not referenced when it can't be decided
+*/
+func structRefs(myStructs []in.MyStruct) map[string]string {
+	refs := make(map[string]string)
+	bareNames := make(map[string]int)
+	for _, m := range myStructs {
+		schemaNames := structSchemaNames(m)
+		ref := ""
+		if own := schemaStructName(m, m.Name); hasKey(schemaNames, own) {
+			ref = own
+		} else if len(schemaNames) == 1 {
+			for name := range schemaNames {
+				ref = name
+			}
+		} else if len(schemaNames) > 1 {
+			perr.AddError(fmt.Sprintf("[Warning] @@struct: %s is in multiple schemas, none named %s, it will not be referenced automatically", m.Name, schemaStructName(m, m.Name)))
+			continue
+		}
+		if ref == "" {
+			continue
+		}
+		refs[m.TypeName()] = ref
+		// used when the field's type couldn't be type checked
+		refs[m.Name] = ref
+		bareNames[m.Name]++
+	}
+	for name, count := range bareNames {
+		if count > 1 {
+			delete(refs, name)
+		}
+	}
+	return refs
+}
+
+func hasKey(names map[string]struct{}, name string) bool {
+	_, ok := names[name]
+	return ok
+}
+
+// all the schema names the struct's fields are in, via the "sw" tag
+func structSchemaNames(myStruct in.MyStruct) map[string]struct{} {
+	names := make(map[string]struct{})
+	for _, f := range myStruct.Fields {
+		tags, err := structtag.Parse(f.Tag)
+		if err != nil {
+			continue
+		}
+		sw, err := tags.Get("sw")
+		if err != nil {
+			continue
+		}
+		for _, schemaName := range strings.Split(sw.Name, ";") {
+			if schemaName == "" {
+				continue
+			}
+			name, _ := determineRequired(schemaName)
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+// import path + type name, i.e. gopkg.in/guregu/null.v4.Int
+func typeName(named *types.Named) string {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
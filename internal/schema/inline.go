@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	in "github.com/blackflagsoftware/go-swagify/internal"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+	"github.com/fatih/structtag"
+)
+
+/*
+a struct that is not marked is an object with its fields inlined, the same rules as a marked struct's fields
+(json tags, embedded structs, validate, sw_desc, ...) with all of them in the object, i.e.
+
+	type Tree struct {
+		Root Node `json:"root" sw:"Tree"`
+	}
+
+	type Node struct {
+		Name     string `json:"name"`
+		Children []Node `json:"children"`
+	}
+
+a struct that contains itself (Node) can't be inlined, the cycle is broken with a $ref to a schema of its own
+and a warning with the path of fields to it, i.e. Tree.Root.Children, mark it to name the schema
+*/
+func (b *structBuild) inlineProperty(goType types.Type, s *types.Struct) SchemaProperty {
+	if s.NumFields() == 0 {
+		return SchemaProperty{Type: "object"}
+	}
+	named, isNamed := goType.(*types.Named)
+	key := ""
+	if isNamed {
+		// includes the type arguments, i.e. Wrapper[User] and Wrapper[Order] are different
+		key = named.String()
+		if name, ok := b.cycles[key]; ok {
+			return SchemaProperty{Ref: "#/components/schemas/" + name}
+		}
+		for _, inlining := range b.inlining {
+			if inlining != key {
+				continue
+			}
+			name := b.cycleSchemaName(named)
+			if b.cycles == nil {
+				b.cycles = make(map[string]string)
+			}
+			b.cycles[key] = name
+			perr.AddError(fmt.Sprintf("[Warning] @@struct: %s: %s is recursive and not marked, it will reference the schema %s, mark it with @@struct to name it", strings.Join(b.path, "."), key, name))
+			return SchemaProperty{Ref: "#/components/schemas/" + name}
+		}
+		b.inlining = append(b.inlining, key)
+		defer func() { b.inlining = b.inlining[:len(b.inlining)-1] }()
+	}
+	// built as a schema of its own while its fields are parsed, the path keeps the name unique
+	inline := "inline:" + strings.Join(b.path, ".")
+	myStruct := in.MyStruct{Name: inline}
+	for _, f := range in.StructFields(goType) {
+		tags, err := structtag.Parse(f.Tag)
+		if err != nil {
+			continue
+		}
+		tags.Set(&structtag.Tag{Key: "sw", Name: inline})
+		f.Tag = tags.String()
+		myStruct.Fields = append(myStruct.Fields, f)
+	}
+	b.buildStruct(myStruct)
+	schema := b.schemas[inline]
+	delete(b.schemas, inline)
+	if name, ok := b.cycles[key]; ok && isNamed {
+		// one of its fields is itself
+		b.schemas[name] = schema
+		return SchemaProperty{Ref: "#/components/schemas/" + name}
+	}
+	schemaProperty := SchemaProperty{Type: "object", Properties: schema.Properties, AllOf: schema.AllOf}
+	if len(schema.Required) > 0 {
+		schemaProperty.Required = schema.Required
+	}
+	return schemaProperty
+}
+
+// the schema name of a recursive struct, named by -schemaNaming, i.e. Node, or with its package name if that is taken, i.e. TreeNode
+func (b *structBuild) cycleSchemaName(named *types.Named) string {
+	name := named.Obj().Name()
+	for i := 0; i < named.TypeArgs().Len(); i++ {
+		name += b.typeArgName(named.TypeArgs().At(i))
+	}
+	if named.Obj().Pkg() == nil {
+		return name
+	}
+	pkg := named.Obj().Pkg()
+	if qualified := qualifiedSchemaName(pkg.Name(), pkg.Path(), name); qualified != name || !b.schemaNameTaken(name) {
+		return qualified
+	}
+	return exportedName(pkg.Name()) + name
+}
+
+func (b *structBuild) schemaNameTaken(name string) bool {
+	if _, ok := b.schemas[name]; ok {
+		return true
+	}
+	for _, taken := range b.refs {
+		if taken == name {
+			return true
+		}
+	}
+	for _, taken := range b.cycles {
+		if taken == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package schema
+
+import (
+	"github.com/blackflagsoftware/go-swagify/config"
+)
+
+/*
+the schema of a go type from the type mappings (-config file or -typeMapping), by its fully qualified
+name (import path + name), i.e. github.com/acme/money.Amount, or, when the field's type could not
+be type checked, by package name + name, i.e. money.Amount
+*/
+func mappedType(names ...string) (SchemaProperty, bool) {
+	for _, name := range names {
+		mapping, ok := config.TypeMappings[name]
+		if !ok {
+			continue
+		}
+		if mapping.Ref != "" {
+			return SchemaProperty{Ref: "#/components/schemas/" + mapping.Ref}, true
+		}
+		schemaProperty := SchemaProperty{Type: mapping.Type, Format: mapping.Format, Pattern: mapping.Pattern, Example: mapping.Example}
+		if schemaProperty.Type == "" {
+			schemaProperty.Type = "string"
+		}
+		if example, ok := mapping.Example.(string); ok {
+			// from the command line all values are strings
+			schemaProperty.Example = enumConv(schemaProperty.Type, example)
+		}
+		return schemaProperty, true
+	}
+	return SchemaProperty{}, false
+}
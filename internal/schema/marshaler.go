@@ -0,0 +1,53 @@
+package schema
+
+import (
+	"fmt"
+	"go/types"
+
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+)
+
+/*
+a type with its own MarshalJSON or MarshalText is not encoded by its fields (encoding/json), the same as
+encoding/json, MarshalJSON is used before MarshalText, both on the value or the pointer
+- json.Marshaler => any, its schema is unknown, add a type mapping (-config, -typeMapping) for it
+- encoding.TextMarshaler => string
+false if the type has neither
+*/
+func (b *structBuild) marshalerProperty(named *types.Named) (SchemaProperty, bool) {
+	if hasMarshalMethod(named, "MarshalJSON") {
+		b.warnOnce(typeName(named), fmt.Sprintf("[Warning] @@struct: %s implements json.Marshaler, add a type mapping (-typeMapping or -config) for its schema, it will be any type", typeName(named)))
+		return SchemaProperty{}, true
+	}
+	if hasMarshalMethod(named, "MarshalText") {
+		return SchemaProperty{Type: "string"}, true
+	}
+	return SchemaProperty{}, false
+}
+
+// the type has the method with the signature: func() ([]byte, error)
+func hasMarshalMethod(named *types.Named, name string) bool {
+	// the pointer's method set has the value's methods too
+	selection := types.NewMethodSet(types.NewPointer(named)).Lookup(named.Obj().Pkg(), name)
+	if selection == nil {
+		return false
+	}
+	signature, ok := selection.Type().(*types.Signature)
+	if !ok || signature.Params().Len() != 0 || signature.Results().Len() != 2 {
+		return false
+	}
+	bytes, ok := signature.Results().At(0).Type().(*types.Slice)
+	return ok && isByte(bytes.Elem()) && signature.Results().At(1).Type().String() == "error"
+}
+
+// the same warning is only added once, i.e. a type used by many fields
+func (b *structBuild) warnOnce(key, warning string) {
+	if b.warned == nil {
+		b.warned = make(map[string]struct{})
+	}
+	if _, ok := b.warned[key]; ok {
+		return
+	}
+	b.warned[key] = struct{}{}
+	perr.AddError(warning)
+}
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"fmt"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/blackflagsoftware/go-swagify/config"
+	in "github.com/blackflagsoftware/go-swagify/internal"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
+	"github.com/fatih/structtag"
+)
+
+/*
+the schema names of the marked structs' fields ("sw" tag) are named by -schemaNaming
+- bare: as is, i.e. User
+- package: prefixed with the struct's package name, i.e. billingUser
+- path: prefixed with the struct's import path, i.e. example.com.acme.billing.User
+@@struct: User as BillingUser names the schema of the struct's name (User) BillingUser, whatever the naming
+
+a schema with the fields of structs from more than one package is most likely two structs of the same name
+that are merged, a warning is added for each
+*/
+func nameSchemas(myStructs []in.MyStruct) []in.MyStruct {
+	switch config.SchemaNaming {
+	case "", "bare", "package", "path":
+	default:
+		perr.AddError(fmt.Sprintf("[Warning] @@struct: invalid -schemaNaming: %s, expected bare, package or path, bare is used", config.SchemaNaming))
+	}
+	named := make([]in.MyStruct, 0, len(myStructs))
+	owners := make(map[string][]in.MyStruct)
+	for _, m := range myStructs {
+		m.Fields = nameFields(m, m.Fields)
+		for name := range structSchemaNames(m) {
+			owners[name] = append(owners[name], m)
+		}
+		named = append(named, m)
+	}
+	schemaNames := []string{}
+	for name := range owners {
+		schemaNames = append(schemaNames, name)
+	}
+	sort.Strings(schemaNames)
+	for _, name := range schemaNames {
+		pkgs := make(map[string]struct{})
+		typeNames := []string{}
+		for _, m := range owners[name] {
+			pkgs[m.Pkg] = struct{}{}
+			typeNames = append(typeNames, m.TypeName())
+		}
+		if len(pkgs) > 1 {
+			perr.AddError(fmt.Sprintf("[Warning] @@struct: the schema %s has the fields of %s, use -schemaNaming or @@struct: <name> as <schema name> to tell them apart", name, strings.Join(typeNames, ", ")))
+		}
+	}
+	return named
+}
+
+// the fields with the schema names of their "sw" tags named for the struct
+func nameFields(myStruct in.MyStruct, fields []in.MyField) []in.MyField {
+	named := make([]in.MyField, len(fields))
+	for i, f := range fields {
+		named[i] = f
+		tags, err := structtag.Parse(f.Tag)
+		if err != nil {
+			continue
+		}
+		sw, err := tags.Get("sw")
+		if err != nil {
+			continue
+		}
+		schemaNames := strings.Split(sw.Name, ";")
+		for j, schemaName := range schemaNames {
+			if schemaName == "" {
+				continue
+			}
+			name, required := determineRequired(schemaName)
+			schemaNames[j] = schemaStructName(myStruct, name)
+			if required {
+				schemaNames[j] += "*"
+			}
+		}
+		if renamed := strings.Join(schemaNames, ";"); renamed != sw.Name {
+			tags.Set(&structtag.Tag{Key: "sw", Name: renamed, Options: sw.Options})
+			named[i].Tag = tags.String()
+		}
+	}
+	return named
+}
+
+func schemaStructName(myStruct in.MyStruct, name string) string {
+	if myStruct.Alias != "" && name == myStruct.Name {
+		return myStruct.Alias
+	}
+	pkgName := path.Base(myStruct.Pkg)
+	if named, ok := myStruct.GoType.(*types.Named); ok && named.Obj().Pkg() != nil {
+		pkgName = named.Obj().Pkg().Name()
+	}
+	return qualifiedSchemaName(pkgName, myStruct.Pkg, name)
+}
+
+func qualifiedSchemaName(pkgName, pkgPath, name string) string {
+	if pkgPath == "" {
+		return name
+	}
+	switch config.SchemaNaming {
+	case "package":
+		return pkgName + name
+	case "path":
+		// a schema name can't have a "/"
+		return strings.ReplaceAll(pkgPath, "/", ".") + "." + name
+	}
+	return name
+}
+
+// the struct (marked or not) of a named type, to name the schemas of its fields
+func (b *structBuild) typeStruct(named *types.Named) in.MyStruct {
+	if myStruct, ok := b.structs[typeName(named)]; ok {
+		return myStruct
+	}
+	myStruct := in.MyStruct{Name: named.Obj().Name(), GoType: named}
+	if named.Obj().Pkg() != nil {
+		myStruct.Pkg = named.Obj().Pkg().Path()
+	}
+	return myStruct
+}
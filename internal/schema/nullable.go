@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/blackflagsoftware/go-swagify/config"
+	"gopkg.in/yaml.v2"
+)
+
+/*
+the property may be null
+- 3.0: nullable: true, a $ref is wrapped in allOf as nothing next to a $ref is used
+- 3.1: type: [<type>, "null"], a $ref is in anyOf with type: "null"
+*/
+func nullable(schemaProperty SchemaProperty) SchemaProperty {
+	if schemaProperty.Ref != "" {
+		if openApi31() {
+			return SchemaProperty{AnyOf: []SchemaProperty{{Ref: schemaProperty.Ref}, {Type: "null"}}}
+		}
+		return SchemaProperty{AllOf: []SchemaProperty{{Ref: schemaProperty.Ref}}, Nullable: true}
+	}
+	if schemaProperty.Type == "" {
+		// any type, null included
+		return schemaProperty
+	}
+	schemaProperty.Nullable = true
+	return schemaProperty
+}
+
+func openApi31() bool {
+	return strings.HasPrefix(config.OpenApiVersion, "3.1")
+}
+
+// without the methods, so it can be marshaled as is
+type property SchemaProperty
+
+/*
+3.1 (JSON Schema) has no nullable, the type is a list of types with "null", and the exclusiveMinimum and
+exclusiveMaximum are the bounds (numbers) instead of a boolean next to the minimum and maximum
+*/
+func (s SchemaProperty) MarshalJSON() ([]byte, error) {
+	if !openApi31() {
+		return json.Marshal(property(s))
+	}
+	var typ interface{}
+	if s.Type != "" {
+		typ = s.Type
+	}
+	if s.Nullable {
+		typ = []string{s.Type, "null"}
+		s.Nullable = false
+	}
+	exclusiveMinimum, exclusiveMaximum := s.exclusiveBounds()
+	if exclusiveMinimum != nil {
+		s.Minimum, s.ExclusiveMinimum = nil, false
+	}
+	if exclusiveMaximum != nil {
+		s.Maximum, s.ExclusiveMaximum = nil, false
+	}
+	// the fields shadow the ones of the property
+	return json.Marshal(struct {
+		Type             interface{} `json:"type,omitempty"`
+		ExclusiveMinimum *float64    `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum *float64    `json:"exclusiveMaximum,omitempty"`
+		property
+	}{typ, exclusiveMinimum, exclusiveMaximum, property(s)})
+}
+
+func (s SchemaProperty) MarshalYAML() (interface{}, error) {
+	exclusiveMinimum, exclusiveMaximum := s.exclusiveBounds()
+	if !openApi31() || (!s.Nullable && exclusiveMinimum == nil && exclusiveMaximum == nil) {
+		return property(s), nil
+	}
+	nullable := s.Nullable
+	s.Nullable = false
+	// yaml won't allow the duplicate keys of an inlined struct, replace them in the marshaled output (keeps the order)
+	out, err := yaml.Marshal(property(s))
+	if err != nil {
+		return nil, err
+	}
+	mapSlice := yaml.MapSlice{}
+	if err := yaml.Unmarshal(out, &mapSlice); err != nil {
+		return nil, err
+	}
+	replaced := yaml.MapSlice{}
+	for _, item := range mapSlice {
+		switch {
+		case item.Key == "type" && nullable:
+			item.Value = []string{s.Type, "null"}
+		case item.Key == "minimum" && exclusiveMinimum != nil, item.Key == "maximum" && exclusiveMaximum != nil:
+			continue
+		case item.Key == "exclusiveMinimum" && exclusiveMinimum != nil:
+			item.Value = *exclusiveMinimum
+		case item.Key == "exclusiveMaximum" && exclusiveMaximum != nil:
+			item.Value = *exclusiveMaximum
+		}
+		replaced = append(replaced, item)
+	}
+	return replaced, nil
+}
+
+// the exclusive minimum and maximum, nil if the bound isn't exclusive
+func (s SchemaProperty) exclusiveBounds() (minimum, maximum *float64) {
+	if s.ExclusiveMinimum && s.Minimum != nil {
+		minimum = s.Minimum
+	}
+	if s.ExclusiveMaximum && s.Maximum != nil {
+		maximum = s.Maximum
+	}
+	return
+}
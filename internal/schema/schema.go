@@ -18,31 +18,69 @@ type (
 		Type           string                    `json:"type,omitempty" yaml:"type,omitempty"`
 		Required       []string                  `json:"required,omitempty" yaml:"required,omitempty"`
 		Description    string                    `json:"description,omitempty" yaml:"description,omitempty"`
-		Example        string                    `json:"example,omitempty" yaml:"example,omitempty"`
+		Deprecated     bool                      `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+		Example        interface{}               `json:"example,omitempty" yaml:"example,omitempty"`
 		Properties     map[string]SchemaProperty `json:"properties,omitempty" yaml:"properties,omitempty"`
 		AddlProperties AdditionalProperty        `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
 		Items          map[string]string         `json:"items,omitempty" yaml:"items,omitempty"`
+		AllOf          []SchemaProperty          `json:"allOf,omitempty" yaml:"allOf,omitempty"`
 	}
 
-	// TODO: if type is object or array may need to have self reference
 	SchemaProperty struct {
-		Ref         string      `json:"$ref,omitempty" yaml:"$ref,omitempty"`
-		Type        string      `json:"type,omitempty" yaml:"type,omitempty"`
-		Description string      `json:"description,omitempty" yaml:"description,omitempty"`
-		Example     interface{} `json:"example,omitempty" yaml:"example,omitempty"`
-		ExampleStr  string      `json:"-" yaml:"-"`
-		Enum        []string    `json:"enum,omitempty" yaml:"enum,omitempty"`
+		Ref         string        `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+		Type        string        `json:"type,omitempty" yaml:"type,omitempty"`
+		Format      string        `json:"format,omitempty" yaml:"format,omitempty"`
+		Description string        `json:"description,omitempty" yaml:"description,omitempty"`
+		Deprecated  bool          `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+		Example     interface{}   `json:"example,omitempty" yaml:"example,omitempty"`
+		ExampleStr  string        `json:"-" yaml:"-"`
+		Enum        []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
+		// constraints, see constraint.go
+		Minimum          *float64         `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+		ExclusiveMinimum bool             `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+		Maximum          *float64         `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+		ExclusiveMaximum bool             `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+		MinLength        *int             `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+		MaxLength        *int             `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+		Pattern          string           `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+		MinItems         *int             `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+		MaxItems         *int             `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+		Nullable         bool             `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+		AllOf            []SchemaProperty `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+		AnyOf            []SchemaProperty `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+		// type => array
+		Items *SchemaProperty `json:"items,omitempty" yaml:"items,omitempty"`
+		// type => object, used for maps
+		AdditionalProperties *SchemaProperty `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+		// type => object, the fields of a struct that is not marked, see inline.go
+		Required   []string                  `json:"required,omitempty" yaml:"required,omitempty"`
+		Properties map[string]SchemaProperty `json:"properties,omitempty" yaml:"properties,omitempty"`
 	}
 
 	AdditionalProperty struct {
 		Type  string            `json:"type,omitempty" yaml:"type,omitempty"`
 		Items map[string]string `json:"items,omitempty" yaml:"items,omitempty"`
 	}
+
+	// helper struct, holds what is known of all the marked structs while their fields are parsed
+	structBuild struct {
+		schemas   map[string]Schema
+		refs      map[string]string      // struct type name => schema name to reference
+		structs   map[string]in.MyStruct // struct type name => marked struct
+		enums     in.Enums
+		warned    map[string]struct{} // the warnings already added, see warnOnce
+		generics  map[string]string   // generic struct type name => schema name of its fields, see generic.go
+		instances map[string]struct{} // schema names of the generic structs' instantiations already built
+		inlining  []string            // type names of the structs being inlined, see inline.go
+		cycles    map[string]string   // type name of a recursive struct that is not marked => its schema name
+		path      []string            // the fields being built, i.e. Category.Parent, for the warnings
+	}
 )
 
 /* go-swagify
 @@schema: <name>
 @@type: (required) [object | array]
+@@example_file: (optional) file with the schema's example, relative to the source file, a json file is parsed
 @@prop_name: <name> (not needed with type => array)
 @@prop_ref: <schema ref>
 @@prop_req: (optional) add to the list of required in Schema; if false just leave omit
@@ -80,30 +118,102 @@ sw_desc:"here is a summary"
 sw_ex:"some example here"
 */
 
-func BuildSchema(comments in.SwagifyComment, schemas map[string]Schema) {
+func BuildSchema(comments in.SwagifyComment, schemas map[string]Schema, refName RefName) {
 	for name, lineArray := range comments.Comments {
 		for _, lines := range lineArray {
-			schema := parseSchemaLines(lines)
+			schema := parseSchemaLines(lines, refName)
 			schemas[name] = schema
 		}
 	}
 	return
 }
 
-func BuildSchemaStruct(myStructs []in.MyStruct) map[string]Schema {
-	schemas := make(map[string]Schema)
+/*
+the schema name of a reference in the comments, an instantiation of a marked generic struct is built (and added
+to the schemas of BuildSchemaStruct), i.e. @@content_ref: Page[User] => PageUser, any other reference is as is
+*/
+type RefName func(ref string) string
+
+// the generic structs are only built for their instantiations, see generic.go, and RefName for the comments' ones
+func BuildSchemaStruct(myStructs []in.MyStruct, enums in.Enums) (map[string]Schema, RefName) {
+	myStructs = nameSchemas(autoStructs(myStructs))
+	build := newStructBuild(myStructs, enums)
 	for _, m := range myStructs {
-		for _, f := range m.Fields {
-			parseTag(f.Name, f.Type, f.Tag, schemas)
+		if len(m.TypeParams) == 0 {
+			build.path = []string{m.Name}
+			build.buildStruct(m)
+			build.describeStruct(m, build.refs[m.TypeName()])
+		}
+	}
+	return build.schemas, build.refName
+}
+
+// the struct's doc comment describes the schema it is referenced by, "Deprecated:" deprecates it
+func (b *structBuild) describeStruct(myStruct in.MyStruct, name string) {
+	schema, ok := b.schemas[name]
+	if !ok || myStruct.Doc == "" {
+		return
+	}
+	if schema.Description == "" {
+		schema.Description = myStruct.Doc
+	}
+	schema.Deprecated = schema.Deprecated || isDeprecated(myStruct.Doc)
+	b.schemas[name] = schema
+}
+
+// a paragraph of the doc comment starts with "Deprecated: ", the go convention
+func isDeprecated(doc string) bool {
+	for _, paragraph := range strings.Split(doc, "\n\n") {
+		if strings.HasPrefix(strings.TrimSpace(paragraph), "Deprecated: ") {
+			return true
 		}
 	}
-	return schemas
+	return false
+}
+
+func (b *structBuild) buildStruct(myStruct in.MyStruct) {
+	fields, embeds := b.structFields(myStruct)
+	for _, f := range fields {
+		b.parseTag(f)
+	}
+	for _, e := range embeds {
+		b.addEmbed(myStruct, e)
+	}
 }
 
-func parseSchemaLines(lines []string) Schema {
+func newStructBuild(myStructs []in.MyStruct, enums in.Enums) *structBuild {
+	structs := make(map[string]in.MyStruct)
+	for _, m := range myStructs {
+		structs[m.TypeName()] = m
+		structs[m.Name] = m
+	}
+	refs := structRefs(myStructs)
+	generics := make(map[string]string)
+	for _, m := range myStructs {
+		if len(m.TypeParams) > 0 {
+			// not referenced as is, only its instantiations are, named after the schema of its fields
+			if ref := refs[m.TypeName()]; ref != "" {
+				generics[m.TypeName()] = ref
+			} else {
+				perr.AddError(fmt.Sprintf("[Warning] @@struct: %s is generic and its fields are not in one schema named %s, its instantiations can't be named", m.Name, schemaStructName(m, m.Name)))
+			}
+			delete(refs, m.TypeName())
+			delete(refs, m.Name)
+		}
+	}
+	return &structBuild{schemas: make(map[string]Schema), refs: refs, structs: structs, enums: enums, generics: generics}
+}
+
+func (b *structBuild) addSchema(name string) {
+	if _, ok := b.schemas[name]; !ok {
+		b.schemas[name] = Schema{Type: "object", Required: []string{}, Properties: make(map[string]SchemaProperty)}
+	}
+}
+
+func parseSchemaLines(lines []string, refName RefName) Schema {
 	schema := Schema{Properties: make(map[string]SchemaProperty), Items: make(map[string]string)}
 	// go through each line and do logic on
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z_/.]+): *?(?P<value>.+)")
 	currentPropertyName := ""
 	schemaProperty := SchemaProperty{}
 	for _, line := range lines {
@@ -122,6 +232,13 @@ func parseSchemaLines(lines []string) Schema {
 			schema.Description = value
 		case "ex":
 			schema.Example = value
+		case "example_file":
+			example, err := in.FileExample(value)
+			if err != nil {
+				perr.AddError(fmt.Sprintf("[Warning] @@schema: unable to read example_file: %s", err))
+				continue
+			}
+			schema.Example = example
 		case "prop_name":
 			if currentPropertyName != value {
 				if currentPropertyName != "" {
@@ -136,7 +253,7 @@ func parseSchemaLines(lines []string) Schema {
 			}
 			currentPropertyName = value
 		case "prop_ref":
-			schemaProperty.Ref = "#/components/schemas/" + value
+			schemaProperty.Ref = "#/components/schemas/" + refName(value)
 			if schema.Type == "array" {
 				schema.Items["$ref"] = schemaProperty.Ref
 			} else {
@@ -154,7 +271,7 @@ func parseSchemaLines(lines []string) Schema {
 		case "prop_ex":
 			schemaProperty.Example = exampleConv(schemaProperty.Type, value)
 		case "addl_prop_ref":
-			ref := "#/components/schemas/" + value
+			ref := "#/components/schemas/" + refName(value)
 			schema.AddlProperties = AdditionalProperty{Type: "array", Items: map[string]string{"$ref": ref}} // TODO: this is only used to handle a map[string]array
 		default:
 			perr.AddError(fmt.Sprintf("[Warning] @@schema: invalid name option: %s", line))
@@ -181,49 +298,111 @@ func blankOutRef(schema *Schema) {
 	}
 }
 
-func parseTag(fieldName, fieldType, fieldTag string, schemas map[string]Schema) {
-	tags, err := structtag.Parse(fieldTag)
+func (b *structBuild) parseTag(field in.MyField) {
+	schemas := b.schemas
+	tags, err := structtag.Parse(field.Tag)
 	if err != nil {
 		fmt.Println("parseTag", err)
 		return
 	}
-	lowerCaseFieldName := determineFieldName(fieldName, tags)
+	lowerCaseFieldName := determineFieldName(field.Name, tags)
+	b.path = append(b.path, field.Name)
+	defer func() { b.path = b.path[:len(b.path)-1] }()
 	sw, err := tags.Get("sw")
 	if err != nil {
 		// unable to find sw tag, ignore field
 		return
 	}
+	schemaProperty := SchemaProperty{}
+	if swRef, errRef := tags.Get("sw_ref"); errRef == nil && swRef.Value() != "" {
+		schemaProperty.Ref = "#/components/schemas/" + b.refName(swRef.Value())
+	} else {
+		schemaProperty = b.typeProperty(field.Type, field.GoType)
+		if swEnum, errEnum := tags.Get("sw_enum"); errEnum == nil {
+			setEnum(&schemaProperty, swEnum.Value())
+		}
+	}
+	if schemaProperty.Ref == "" {
+		typeDesc, typeExample := schemaProperty.Description, schemaProperty.Example
+		schemaProperty.Description, schemaProperty.Example = parseSwagifyTag(field.Name, schemaProperty, tags)
+		if _, errDesc := tags.Get("sw_desc"); errDesc != nil && field.Doc != "" {
+			schemaProperty.Description = field.Doc
+		} else if errDesc != nil && typeDesc != "" {
+			// the description of the type (i.e. time.Duration's unit) over the field's name
+			schemaProperty.Description = typeDesc
+		}
+		schemaProperty.Deprecated = isDeprecated(field.Doc)
+		if _, errEx := tags.Get("sw_ex"); errEx != nil && typeExample != nil {
+			// the example of the type's mapping over the field's name
+			schemaProperty.Example = typeExample
+		}
+	}
+	// the constraints are checked for a $ref too, it may be required
+	ref := schemaProperty.Ref
+	tagRequired := applyConstraints(&schemaProperty, tags)
+	if _, errEx := tags.Get("sw_ex"); errEx != nil && len(schemaProperty.Enum) > 0 && !inEnum(schemaProperty.Enum, schemaProperty.Example) {
+		// the field's name is not one of the values
+		schemaProperty.Example = schemaProperty.Enum[0]
+	}
+	if appTag, errApp := tags.Get(config.AppOutputFormat); errApp == nil && appTag.HasOption("string") && ref == "" {
+		// after the constraints, they are the ones of the go type
+		stringEncoded(&schemaProperty)
+	}
+	if config.RequiredPolicy == "omitempty" {
+		// without omitempty the field is always in the output
+		if appTag, errApp := tags.Get(config.AppOutputFormat); errApp != nil || !appTag.HasOption("omitempty") {
+			tagRequired = true
+		}
+	}
+	if ref != "" {
+		schemaProperty = SchemaProperty{Ref: ref}
+	}
 	// split possible schema names; i.e. ExampleRequest*;ExampleResponse => [ExampleRequest*, ExampleResponse]
 	schemaNames := strings.Split(sw.Name, ";")
 	for _, schemaName := range schemaNames {
 		name, required := determineRequired(schemaName)
-		if _, ok := schemas[name]; !ok {
-			schemas[name] = Schema{Type: "object", Required: []string{}, Properties: make(map[string]SchemaProperty)}
-		}
-		if required {
+		b.addSchema(name)
+		if required || tagRequired {
 			schema := schemas[name]
 			schema.Required = append(schema.Required, lowerCaseFieldName)
 			schemas[name] = schema
 		}
-		var example interface{}
-		docType, desc, ref := "", "", ""
-		swRef, errRef := tags.Get("sw_ref")
-		if swRef == nil || swRef.Value() == "" || errRef != nil {
-			docType, desc, example = parseSwagifyTag(fieldName, fieldType, tags)
-		}
-		if swRef != nil {
-			ref = "#/components/schemas/" + swRef.Value()
-		}
-		schemaProperty := SchemaProperty{
-			Ref:         ref,
-			Type:        docType,
-			Description: desc,
-			Example:     example,
-		}
 		schemas[name].Properties[lowerCaseFieldName] = schemaProperty
 	}
 }
 
+// the patterns of the numbers and booleans encoded as a string
+var stringEncodedPatterns = map[string]string{
+	"integer": "^-?[0-9]+$",
+	"number":  "^-?[0-9]+(\\.[0-9]+)?([eE][-+]?[0-9]+)?$",
+	"boolean": "^(true|false)$",
+}
+
+/*
+encoding/json encodes numbers and booleans as a string, json:",string", the minimum and maximum can't be
+those of a string so the pattern of the number is used instead (unless there's one), the enum and example
+are strings
+*/
+func stringEncoded(schemaProperty *SchemaProperty) {
+	pattern, ok := stringEncodedPatterns[schemaProperty.Type]
+	if !ok {
+		return
+	}
+	schemaProperty.Type = "string"
+	schemaProperty.Format = ""
+	schemaProperty.Minimum, schemaProperty.Maximum = nil, nil
+	schemaProperty.ExclusiveMinimum, schemaProperty.ExclusiveMaximum = false, false
+	if schemaProperty.Pattern == "" {
+		schemaProperty.Pattern = pattern
+	}
+	for i, value := range schemaProperty.Enum {
+		schemaProperty.Enum[i] = fmt.Sprint(value)
+	}
+	if schemaProperty.Example != nil {
+		schemaProperty.Example = fmt.Sprint(schemaProperty.Example)
+	}
+}
+
 func determineRequired(schemaName string) (string, bool) {
 	if schemaName[len(schemaName)-1:] == "*" {
 		return schemaName[:len(schemaName)-1], true
@@ -235,22 +414,15 @@ func determineFieldName(fieldName string, tags *structtag.Tags) string {
 	altFieldName := util.BuildAlternateFieldName(fieldName, config.AltFieldFormat)
 	// name := strings.ToLower(altFieldName)
 	tag, err := tags.Get(config.AppOutputFormat)
-	if err != nil {
+	if err != nil || tag.Name == "" {
+		// i.e. json:",omitempty"
 		return altFieldName
 	}
 	return tag.Name
 }
 
-func parseSwagifyTag(fieldName, fieldType string, tags *structtag.Tags) (docType string, desc string, example interface{}) {
-	docType = "string"
-	switch fieldType {
-	case "float32", "float64", "null.Float":
-		docType = "number"
-	case "int", "int32", "int64", "null.Int":
-		docType = "integer"
-	case "bool", "null.Bool":
-		docType = "boolean"
-	}
+func parseSwagifyTag(fieldName string, schemaProperty SchemaProperty, tags *structtag.Tags) (desc string, example interface{}) {
+	docType := schemaProperty.Type
 	if swDesc, errDesc := tags.Get("sw_desc"); errDesc != nil {
 		if jsonDesc, err := tags.Get(config.OutputFormat); err != nil {
 			desc = strings.ToLower(fieldName)
@@ -261,11 +433,22 @@ func parseSwagifyTag(fieldName, fieldType string, tags *structtag.Tags) (docType
 		desc = swDesc.Name
 	}
 	if swEx, errEx := tags.Get("sw_ex"); errEx != nil {
+		if docType == "array" || docType == "object" || docType == "" {
+			// the name of the field would not be a valid example
+			return
+		}
 		if jsonEx, err := tags.Get(config.OutputFormat); err != nil {
 			example = strings.ToLower(fieldName)
 		} else {
 			example = jsonEx.Name
 		}
+	} else if docType == "array" && schemaProperty.Items != nil {
+		// each value separated by ',' is an item
+		examples := []interface{}{}
+		for _, ex := range append([]string{swEx.Name}, swEx.Options...) {
+			examples = append(examples, exampleConv(schemaProperty.Items.Type, strings.TrimSpace(ex)))
+		}
+		example = examples
 	} else {
 		example = exampleConv(docType, swEx.Name)
 		if len(swEx.Options) > 0 && docType == "string" && swEx.Options[0] != "omitempty" {
@@ -299,6 +482,65 @@ func exampleConv(docType string, exampleStr string) (example interface{}) {
 	return
 }
 
+// sw_enum:"a;b;c", for an array the values are the items' enum
+func setEnum(schemaProperty *SchemaProperty, value string) {
+	if schemaProperty.Type == "array" && schemaProperty.Items != nil {
+		setEnum(schemaProperty.Items, value)
+		return
+	}
+	schemaProperty.Ref = ""
+	if schemaProperty.Type == "" {
+		schemaProperty.Type = "string"
+	}
+	schemaProperty.Enum = []interface{}{}
+	for _, v := range strings.Split(value, ";") {
+		schemaProperty.Enum = append(schemaProperty.Enum, enumConv(schemaProperty.Type, strings.TrimSpace(v)))
+	}
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, v := range enum {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func enumConv(docType string, value string) interface{} {
+	switch docType {
+	case "integer":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// the schema type of the values of an enum
+func EnumType(values []interface{}) string {
+	if len(values) == 0 {
+		return "string"
+	}
+	switch values[0].(type) {
+	case int64:
+		return "integer"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "string"
+}
+
 func validateType(t string) string {
 	types := map[string]struct{}{"string": {}, "number": {}, "interger": {}, "boolean": {}, "object": {}, "array": {}}
 	if _, ok := types[t]; !ok {
@@ -1,9 +1,19 @@
 package schema
 
 import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
 	"testing"
 
+	"github.com/blackflagsoftware/go-swagify/config"
+	in "github.com/blackflagsoftware/go-swagify/internal"
+	"github.com/fatih/structtag"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 )
 
 func Test_parseTag(t *testing.T) {
@@ -33,7 +43,8 @@ func Test_parseTag(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parseTag(tt.args.fieldName, tt.args.fieldType, tt.args.tagValue, tt.args.schemas)
+			build := &structBuild{schemas: tt.args.schemas}
+			build.parseTag(in.MyField{Name: tt.args.fieldName, Type: tt.args.fieldType, Tag: tt.args.tagValue})
 			_, ok := tt.args.schemas[tt.wantKey]
 			assert.Equal(t, true, ok, "No key")
 			for v, k := range tt.args.schemas {
@@ -45,6 +56,413 @@ func Test_parseTag(t *testing.T) {
 	}
 }
 
+func TestBuildSchemaStruct_structRef(t *testing.T) {
+	pkg := types.NewPackage("example.com/acme/user", "user")
+	address := types.NewNamed(types.NewTypeName(0, pkg, "Address", nil), types.NewStruct(nil, nil), nil)
+	myStructs := []in.MyStruct{
+		{Name: "Address", Pkg: pkg.Path(), Fields: []in.MyField{{Name: "Street", Type: "string", Tag: `json:"street" sw:"Address"`}}},
+		{Name: "User", Pkg: pkg.Path(), Fields: []in.MyField{
+			{Name: "Home", Type: "Address", Tag: `json:"home" sw:"User"`, GoType: address},
+			{Name: "Work", Type: "*Address", Tag: `json:"work" sw:"User"`, GoType: types.NewPointer(address)},
+			{Name: "Other", Type: "Address", Tag: `json:"other" sw:"User"`},
+			{Name: "Manual", Type: "Address", Tag: `json:"manual" sw:"User" sw_ref:"Location"`, GoType: address},
+		}},
+	}
+	schemas, _ := BuildSchemaStruct(myStructs, nil)
+	user := schemas["User"]
+	assert.Equal(t, "#/components/schemas/Address", user.Properties["home"].Ref)
+	assert.Equal(t, []SchemaProperty{{Ref: "#/components/schemas/Address"}}, user.Properties["work"].AllOf)
+	assert.True(t, user.Properties["work"].Nullable)
+	assert.Equal(t, "#/components/schemas/Address", user.Properties["other"].Ref)
+	assert.Equal(t, "#/components/schemas/Location", user.Properties["manual"].Ref)
+	assert.Equal(t, "", user.Properties["home"].Type)
+}
+
+func TestBuildSchemaStruct_embedded(t *testing.T) {
+	config.AppOutputFormat = "json"
+	defer func() { config.AppOutputFormat = "" }()
+	pkg := types.NewPackage("example.com/acme/user", "user")
+	field := func(name string, typ types.Type, embedded bool) *types.Var {
+		return types.NewField(0, pkg, name, typ, embedded)
+	}
+	audit := types.NewNamed(types.NewTypeName(0, pkg, "Audit", nil), types.NewStruct(
+		[]*types.Var{field("CreatedBy", types.Typ[types.String], false), field("Note", types.Typ[types.String], false)},
+		[]string{`json:"created_by"`, `json:"note"`},
+	), nil)
+	base := types.NewNamed(types.NewTypeName(0, pkg, "Base", nil), types.NewStruct(
+		[]*types.Var{field("ID", types.Typ[types.Int64], false), field("secret", types.Typ[types.String], false), field("Audit", audit, true)},
+		[]string{`json:"id"`, ``, ``},
+	), nil)
+	myStructs := []in.MyStruct{
+		{Name: "Audit", Pkg: pkg.Path(), Fields: []in.MyField{{Name: "CreatedBy", Type: "string", Tag: `json:"created_by" sw:"Audit"`}}},
+		{Name: "User", Pkg: pkg.Path(), Fields: []in.MyField{
+			{Name: "Base", Type: "*Base", GoType: types.NewPointer(base), Embedded: true},
+			{Name: "Name", Type: "string", Tag: `json:"name" sw:"User*;UserResponse"`},
+			{Name: "Note", Type: "string", Tag: `json:"note"`},
+		}},
+		{Name: "Account", Pkg: pkg.Path(), Fields: []in.MyField{
+			{Name: "Audit", Type: "Audit", Tag: `sw_embed:"allOf"`, GoType: audit, Embedded: true},
+			{Name: "Number", Type: "string", Tag: `json:"number" sw:"Account"`},
+		}},
+	}
+	schemas, _ := BuildSchemaStruct(myStructs, nil)
+	for _, name := range []string{"User", "UserResponse"} {
+		props := schemas[name].Properties
+		assert.Contains(t, props, "id", name)
+		assert.Contains(t, props, "created_by", name)
+		assert.Contains(t, props, "name", name)
+		assert.NotContains(t, props, "secret", name)
+		assert.NotContains(t, props, "note", name)
+	}
+	assert.Equal(t, []string{"name"}, schemas["User"].Required)
+	assert.Equal(t, []SchemaProperty{{Ref: "#/components/schemas/Audit"}}, schemas["Account"].AllOf)
+	assert.NotContains(t, schemas["Account"].Properties, "created_by")
+}
+
+func TestBuildSchemaStruct_enum(t *testing.T) {
+	pkg := types.NewPackage("example.com/acme/order", "order")
+	status := types.NewNamed(types.NewTypeName(0, pkg, "Status", nil), types.Typ[types.String], nil)
+	enums := in.Enums{"example.com/acme/order.Status": {"open", "closed"}}
+	myStructs := []in.MyStruct{
+		{Name: "Order", Pkg: pkg.Path(), Fields: []in.MyField{
+			{Name: "Status", Type: "Status", Tag: `json:"status" sw:"Order"`, GoType: status},
+			{Name: "History", Type: "[]Status", Tag: `json:"history" sw:"Order"`, GoType: types.NewSlice(status)},
+			{Name: "Priority", Type: "int", Tag: `json:"priority" sw:"Order" sw_enum:"1;2;3"`, GoType: types.Typ[types.Int]},
+			{Name: "Next", Type: "Status", Tag: `json:"next" sw:"Order" sw_ex:"closed"`, GoType: status},
+		}},
+	}
+	schemas, _ := BuildSchemaStruct(myStructs, enums)
+	props := schemas["Order"].Properties
+	assert.Equal(t, []interface{}{"open", "closed"}, props["status"].Enum)
+	assert.Equal(t, "string", props["status"].Type)
+	assert.Equal(t, []interface{}{"open", "closed"}, props["history"].Items.Enum)
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(3)}, props["priority"].Enum)
+	// the first value, not the field's name
+	assert.Equal(t, "open", props["status"].Example)
+	assert.Equal(t, int64(1), props["priority"].Example)
+	assert.Equal(t, "closed", props["next"].Example)
+}
+
+func TestBuildSchemaStruct_generic(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "page.go", `package page
+
+type Page[T any] struct {
+	Items []T    `+"`json:\"items\" sw:\"Page*\"`"+`
+	Next  string `+"`json:\"next\" sw:\"Page\"`"+`
+}
+
+type User struct{}
+
+type Listing struct {
+	Users Page[User]
+}
+`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("example.com/acme/page", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(name string) types.Type { return pkg.Scope().Lookup(name).Type() }
+	myStructs := []in.MyStruct{
+		{Name: "Page", Pkg: pkg.Path(), TypeParams: []string{"T"}, GoType: lookup("Page"), Fields: []in.MyField{
+			{Name: "Items", Type: "[]T", Tag: `json:"items" sw:"Page*"`},
+			{Name: "Next", Type: "string", Tag: `json:"next" sw:"Page"`},
+		}},
+		{Name: "User", Pkg: pkg.Path(), GoType: lookup("User"), Fields: []in.MyField{{Name: "Name", Type: "string", Tag: `json:"name" sw:"User"`}}},
+		{Name: "Listing", Pkg: pkg.Path(), GoType: lookup("Listing"), Fields: []in.MyField{
+			{Name: "Users", Type: "Page[User]", Tag: `json:"users" sw:"Listing"`, GoType: lookup("Listing").Underlying().(*types.Struct).Field(0).Type()},
+		}},
+	}
+	schemas, refName := BuildSchemaStruct(myStructs, nil)
+	assert.NotContains(t, schemas, "Page")
+	assert.Equal(t, "#/components/schemas/PageUser", schemas["Listing"].Properties["users"].Ref)
+	if assert.Contains(t, schemas, "PageUser") {
+		assert.Equal(t, []string{"items"}, schemas["PageUser"].Required)
+		assert.Equal(t, "#/components/schemas/User", schemas["PageUser"].Properties["items"].Items.Ref)
+		assert.Contains(t, schemas["PageUser"].Properties, "next")
+	}
+	// from the comments, i.e. @@content_ref: Page[[]User]
+	config.GenericName = `{{.Name}}Of{{.Args | join "And"}}`
+	defer func() { config.GenericName = "" }()
+	assert.Equal(t, "PageOfUserList", refName("Page[[]User]"))
+	assert.Equal(t, "#/components/schemas/User", schemas["PageOfUserList"].Properties["items"].Items.Items.Ref)
+	assert.Equal(t, "PageOfString", refName("Page[string]"))
+	assert.Equal(t, "User", refName("User"))
+	assert.Equal(t, "Other[User]", refName("Other[User]"))
+}
+
+func TestBuildSchemaStruct_genericUnnamed(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "page.go", `package page
+
+type Page[T any] struct {
+	Items []T    `+"`json:\"items\" sw:\"PageA;PageB\"`"+`
+	Next  string `+"`json:\"next\" sw:\"PageA\"`"+`
+}
+
+type Item struct{}
+
+type Listing struct {
+	Items Page[Item]
+}
+`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("example.com/acme/page", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(name string) types.Type { return pkg.Scope().Lookup(name).Type() }
+	myStructs := []in.MyStruct{
+		{Name: "Page", Pkg: pkg.Path(), TypeParams: []string{"T"}, GoType: lookup("Page"), Fields: []in.MyField{
+			{Name: "Items", Type: "[]T", Tag: `json:"items" sw:"PageA;PageB"`},
+			{Name: "Next", Type: "string", Tag: `json:"next" sw:"PageA"`},
+		}},
+		{Name: "Item", Pkg: pkg.Path(), GoType: lookup("Item"), Fields: []in.MyField{{Name: "Name", Type: "string", Tag: `json:"name" sw:"Item"`}}},
+		{Name: "Listing", Pkg: pkg.Path(), GoType: lookup("Listing"), Fields: []in.MyField{
+			{Name: "Items", Type: "Page[Item]", Tag: `json:"items" sw:"Listing"`, GoType: lookup("Listing").Underlying().(*types.Struct).Field(0).Type()},
+		}},
+	}
+	schemas, refName := BuildSchemaStruct(myStructs, nil)
+	// the instantiation has no name, it's not the type argument's schema
+	assert.NotEqual(t, "#/components/schemas/Item", schemas["Listing"].Properties["items"].Ref)
+	assert.Equal(t, "", schemas["Listing"].Properties["items"].Ref)
+	assert.Equal(t, "Page[Item]", refName("Page[Item]"))
+}
+
+func TestBuildSchemaStruct_cycle(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "tree.go", `package tree
+
+type Tree struct {
+	Root Node
+	Meta Meta
+	Cat  *Category
+}
+
+type Node struct {
+	Name     string `+"`json:\"name\"`"+`
+	Children []Node `+"`json:\"children\"`"+`
+}
+
+type Meta struct {
+	Version int `+"`json:\"version\"`"+`
+	hidden  int
+}
+
+type Category struct {
+	Parent *Category
+}
+`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("example.com/acme/tree", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := pkg.Scope().Lookup("Tree").Type().Underlying().(*types.Struct)
+	category := pkg.Scope().Lookup("Category").Type()
+	myStructs := []in.MyStruct{
+		{Name: "Tree", Pkg: pkg.Path(), Fields: []in.MyField{
+			{Name: "Root", Type: "Node", Tag: `json:"root" sw:"Tree"`, GoType: tree.Field(0).Type()},
+			{Name: "Meta", Type: "Meta", Tag: `json:"meta" sw:"Tree"`, GoType: tree.Field(1).Type()},
+			{Name: "Cat", Type: "*Category", Tag: `json:"cat" sw:"Tree"`, GoType: tree.Field(2).Type()},
+		}},
+		{Name: "Category", Pkg: pkg.Path(), Fields: []in.MyField{
+			{Name: "Parent", Type: "*Category", Tag: `json:"parent" sw:"Category"`, GoType: types.NewPointer(category)},
+		}},
+	}
+	schemas, _ := BuildSchemaStruct(myStructs, nil)
+	props := schemas["Tree"].Properties
+	// not recursive, inlined
+	assert.Equal(t, "object", props["meta"].Type)
+	assert.Equal(t, []string{"version"}, sortedKeys(props["meta"].Properties))
+	// recursive, a schema of its own
+	assert.Equal(t, "#/components/schemas/Node", props["root"].Ref)
+	if assert.Contains(t, schemas, "Node") {
+		assert.Equal(t, "#/components/schemas/Node", schemas["Node"].Properties["children"].Items.Ref)
+	}
+	// marked, always a $ref
+	assert.Equal(t, []SchemaProperty{{Ref: "#/components/schemas/Category"}}, props["cat"].AllOf)
+	assert.Equal(t, []SchemaProperty{{Ref: "#/components/schemas/Category"}}, schemas["Category"].Properties["parent"].AllOf)
+	for name := range schemas {
+		assert.NotContains(t, name, "inline:")
+	}
+}
+
+func TestBuildSchemaStruct_naming(t *testing.T) {
+	defer func() { config.SchemaNaming = "" }()
+	myStructs := []in.MyStruct{
+		{Name: "User", Pkg: "example.com/acme/user", Fields: []in.MyField{
+			{Name: "Name", Type: "string", Tag: `json:"name" sw:"User*;UserList"`},
+		}},
+		{Name: "User", Pkg: "example.com/acme/billing", Fields: []in.MyField{
+			{Name: "Plan", Type: "string", Tag: `json:"plan" sw:"User"`},
+		}},
+		{Name: "Invoice", Pkg: "example.com/acme/billing", Fields: []in.MyField{
+			{Name: "Total", Type: "float64", Tag: `json:"total" sw:"Invoice"`},
+		}},
+	}
+	// both are merged into User
+	schemas, _ := BuildSchemaStruct(myStructs, nil)
+	assert.Equal(t, []string{"name", "plan"}, sortedKeys(schemas["User"].Properties))
+	config.SchemaNaming = "package"
+	schemas, _ = BuildSchemaStruct(myStructs, nil)
+	assert.Equal(t, []string{"billingInvoice", "billingUser", "userUser", "userUserList"}, sortedKeys(schemas))
+	assert.Equal(t, []string{"name"}, schemas["userUser"].Required)
+	config.SchemaNaming = "path"
+	schemas, _ = BuildSchemaStruct(myStructs, nil)
+	assert.Contains(t, schemas, "example.com.acme.billing.User")
+	// the alias is not named by -schemaNaming
+	myStructs[1].Alias = "BillingUser"
+	schemas, _ = BuildSchemaStruct(myStructs, nil)
+	assert.Contains(t, schemas, "BillingUser")
+	assert.Contains(t, schemas, "example.com.acme.billing.Invoice")
+	config.SchemaNaming = ""
+	schemas, _ = BuildSchemaStruct(myStructs, nil)
+	assert.Equal(t, []string{"BillingUser", "Invoice", "User", "UserList"}, sortedKeys(schemas))
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestBuildSchemaStruct_auto(t *testing.T) {
+	config.AppOutputFormat = "json"
+	defer func() { config.AppOutputFormat = "" }()
+	myStructs := []in.MyStruct{
+		{Name: "Address", Auto: true, Fields: []in.MyField{
+			{Name: "Street", Type: "string", Tag: `json:"street"`, Doc: "the street and number"},
+			{Name: "City", Type: "string", Doc: "the city"},
+			{Name: "Secret", Type: "string", Tag: `sw:"-"`},
+			{Name: "Zip", Type: "string", Tag: `json:"zip" sw:"*"`},
+			{Name: "Country", Type: "string", Tag: `json:"country" sw:"Address;Location" sw_desc:"iso code"`},
+			{Name: "hidden", Type: "string"},
+		}},
+	}
+	schemas, _ := BuildSchemaStruct(myStructs, nil)
+	props := schemas["Address"].Properties
+	assert.Equal(t, []string{"city", "country", "street", "zip"}, sortedKeys(props))
+	assert.Equal(t, "the street and number", props["street"].Description)
+	assert.Equal(t, "iso code", props["country"].Description)
+	assert.Equal(t, []string{"zip"}, schemas["Address"].Required)
+	assert.Contains(t, schemas["Location"].Properties, "country")
+}
+
+func TestBuildSchemaStruct_doc(t *testing.T) {
+	myStructs := []in.MyStruct{
+		{Name: "Account", Doc: "Account of a customer.\n\nDeprecated: use Customer.", Fields: []in.MyField{
+			{Name: "Number", Type: "string", Tag: `json:"number" sw:"Account;AccountRequest"`, Doc: "Number of the account."},
+			{Name: "Old", Type: "string", Tag: `json:"old" sw:"Account"`, Doc: "Old number.\n\nDeprecated: use Number."},
+			{Name: "Note", Type: "string", Tag: `json:"note" sw:"Account" sw_desc:"a note"`, Doc: "the note"},
+			{Name: "Tag", Type: "string", Tag: `json:"tag" sw:"Account"`},
+		}},
+	}
+	schemas, _ := BuildSchemaStruct(myStructs, nil)
+	account := schemas["Account"]
+	assert.Equal(t, "Account of a customer.\n\nDeprecated: use Customer.", account.Description)
+	assert.True(t, account.Deprecated)
+	assert.Equal(t, "", schemas["AccountRequest"].Description)
+	assert.Equal(t, "Number of the account.", account.Properties["number"].Description)
+	assert.False(t, account.Properties["number"].Deprecated)
+	assert.True(t, account.Properties["old"].Deprecated)
+	assert.Equal(t, "a note", account.Properties["note"].Description)
+	assert.Equal(t, "tag", account.Properties["tag"].Description)
+}
+
+func TestBuildSchemaStruct_jsonTags(t *testing.T) {
+	config.AppOutputFormat = "json"
+	config.RequiredPolicy = "omitempty"
+	defer func() {
+		config.AppOutputFormat = ""
+		config.RequiredPolicy = ""
+	}()
+	myStructs := []in.MyStruct{
+		{Name: "User", Fields: []in.MyField{
+			{Name: "Password", Type: "string", Tag: `json:"-" sw:"User"`},
+			{Name: "Dash", Type: "string", Tag: `json:"-," sw:"User"`},
+			{Name: "secret", Type: "string", Tag: `json:"secret" sw:"User"`},
+			{Name: "Count", Type: "int64", Tag: `json:"count,string,omitempty" sw:"User"`},
+			{Name: "Name", Type: "string", Tag: `json:",omitempty" sw:"User"`},
+		}},
+	}
+	schemas, _ := BuildSchemaStruct(myStructs, nil)
+	schema := schemas["User"]
+	assert.NotContains(t, schema.Properties, "password")
+	assert.Equal(t, "dash", schema.Properties["-"].Description, "json:\"-,\"")
+	assert.NotContains(t, schema.Properties, "secret")
+	assert.Equal(t, "string", schema.Properties["count"].Type)
+	assert.Equal(t, "", schema.Properties["count"].Format)
+	assert.Contains(t, schema.Properties, "name")
+	assert.Equal(t, []string{"-"}, schema.Required)
+}
+
+func TestBuildSchemaStruct_stringEncoded(t *testing.T) {
+	config.AppOutputFormat = "json"
+	config.ValidateTag = "validate"
+	defer func() {
+		config.AppOutputFormat = ""
+		config.ValidateTag = ""
+	}()
+	myStructs := []in.MyStruct{
+		{Name: "Order", Fields: []in.MyField{
+			{Name: "Count", Type: "int", Tag: `json:"count,string" validate:"gt=0,max=10" sw:"Order" sw_ex:"5"`},
+			{Name: "Price", Type: "float64", Tag: `json:"price,string" validate:"oneof=1.5 2.5" sw:"Order"`},
+			{Name: "Code", Type: "int", Tag: `json:"code,string" sw_pattern:"^[0-9]{4}$" sw:"Order"`},
+			{Name: "Open", Type: "bool", Tag: `json:"open,string" sw:"Order"`},
+		}},
+	}
+	schemas, _ := BuildSchemaStruct(myStructs, nil)
+	schema := schemas["Order"]
+	count := schema.Properties["count"]
+	assert.Equal(t, "string", count.Type)
+	assert.Equal(t, "^-?[0-9]+$", count.Pattern)
+	// the bounds of the number are not the length of the string
+	assert.Nil(t, count.Minimum)
+	assert.Nil(t, count.Maximum)
+	assert.Nil(t, count.MinLength)
+	assert.Nil(t, count.MaxLength)
+	assert.Equal(t, "5", count.Example)
+	price := schema.Properties["price"]
+	assert.Equal(t, "string", price.Type)
+	assert.Equal(t, []interface{}{"1.5", "2.5"}, price.Enum)
+	assert.Equal(t, "^[0-9]{4}$", schema.Properties["code"].Pattern)
+	assert.Equal(t, "^(true|false)$", schema.Properties["open"].Pattern)
+}
+
+func TestBuildSchemaStruct_wellKnown(t *testing.T) {
+	config.AppOutputFormat = "json"
+	defer func() { config.AppOutputFormat = "" }()
+	myStructs := []in.MyStruct{
+		{Name: "Job", Fields: []in.MyField{
+			{Name: "Timeout", Type: "time.Duration", Tag: `json:"timeout" sw:"Job"`},
+			{Name: "Delay", Type: "time.Duration", Tag: `json:"delay" sw:"Job" sw_desc:"before the first run"`},
+			{Name: "Host", Type: "net.IP", Tag: `json:"host" sw:"Job"`},
+			{Name: "Peer", Type: "net.IP", Tag: `json:"peer" sw:"Job" sw_format:"ipv6"`},
+		}},
+	}
+	schemas, _ := BuildSchemaStruct(myStructs, nil)
+	schema := schemas["Job"]
+	timeout := schema.Properties["timeout"]
+	assert.Equal(t, "integer", timeout.Type)
+	assert.Equal(t, "int64", timeout.Format)
+	assert.Equal(t, "nanoseconds", timeout.Description)
+	assert.Equal(t, "before the first run", schema.Properties["delay"].Description)
+	assert.Equal(t, "string", schema.Properties["host"].Type)
+	assert.Equal(t, "", schema.Properties["host"].Format)
+	assert.Equal(t, "ipv6", schema.Properties["peer"].Format)
+}
+
 func Test_determineRequired(t *testing.T) {
 	type args struct {
 		schemaName string
@@ -76,3 +494,228 @@ func Test_determineRequired(t *testing.T) {
 		})
 	}
 }
+
+func Test_typeProperty(t *testing.T) {
+	pkg := types.NewPackage("example.com/acme/user", "user")
+	nullPkg := types.NewPackage("gopkg.in/guregu/null.v4", "null")
+	userID := types.NewNamed(types.NewTypeName(0, pkg, "UserID", nil), types.Typ[types.Int64], nil)
+	money := types.NewNamed(types.NewTypeName(0, pkg, "Money", nil), types.Typ[types.Float64], nil)
+	nullInt := types.NewNamed(types.NewTypeName(0, nullPkg, "Int", nil), types.NewStruct(nil, nil), nil)
+	tag := types.NewNamed(types.NewTypeName(0, pkg, "Tag", nil), types.NewStruct(nil, nil), nil)
+	tags := types.NewNamed(types.NewTypeName(0, pkg, "Tags", nil), types.NewSlice(types.Typ[types.String]), nil)
+	timePkg := types.NewPackage("time", "time")
+	timeTime := types.NewNamed(types.NewTypeName(0, timePkg, "Time", nil), types.NewStruct(nil, nil), nil)
+	timeDuration := types.NewNamed(types.NewTypeName(0, timePkg, "Duration", nil), types.Typ[types.Int64], nil)
+	netIP := types.NewNamed(types.NewTypeName(0, types.NewPackage("net", "net"), "IP", nil), types.NewSlice(types.Typ[types.Byte]), nil)
+	sqlNullString := types.NewNamed(types.NewTypeName(0, types.NewPackage("database/sql", "sql"), "NullString", nil), types.NewStruct(nil, nil), nil)
+	typeParam := types.NewTypeParam(types.NewTypeName(0, pkg, "T", nil), types.NewInterfaceType(nil, nil))
+	optional := types.NewNamed(types.NewTypeName(0, pkg, "Optional", nil), nil, nil)
+	optional.SetTypeParams([]*types.TypeParam{typeParam})
+	optional.SetUnderlying(types.NewStruct([]*types.Var{types.NewField(0, pkg, "Value", typeParam, false)}, nil))
+	optionalUserID, err := types.Instantiate(nil, optional, []types.Type{userID}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	build := &structBuild{refs: map[string]string{"example.com/acme/user.Tag": "Tag", "Tag": "Tag"}}
+	type args struct {
+		fieldType string
+		goType    types.Type
+	}
+	tests := []struct {
+		name string
+		args args
+		want SchemaProperty
+	}{
+		{"source text", args{"int32", nil}, SchemaProperty{Type: "integer", Format: "int32"}},
+		{"source text named", args{"null.Bool", nil}, SchemaProperty{Type: "boolean", Nullable: true}},
+		{"source text unknown", args{"UserID", nil}, SchemaProperty{Type: "string"}},
+		{"source text container", args{"map[string][]*Tag", nil}, SchemaProperty{Type: "object", AdditionalProperties: &SchemaProperty{Type: "array", Items: &SchemaProperty{AllOf: []SchemaProperty{{Ref: "#/components/schemas/Tag"}}, Nullable: true}}}},
+		{"named int64", args{"UserID", userID}, SchemaProperty{Type: "integer", Format: "int64"}},
+		{"named float64", args{"Money", money}, SchemaProperty{Type: "number", Format: "double"}},
+		{"pointer to named", args{"*UserID", types.NewPointer(userID)}, SchemaProperty{Type: "integer", Format: "int64", Nullable: true}},
+		{"null type", args{"null.Int", nullInt}, SchemaProperty{Type: "integer", Format: "int64", Nullable: true}},
+		{"sql null type", args{"sql.NullString", sqlNullString}, SchemaProperty{Type: "string", Nullable: true}},
+		{"generic null type", args{"Optional[UserID]", optionalUserID}, SchemaProperty{Type: "integer", Format: "int64", Nullable: true}},
+		{"struct", args{"struct{}", types.NewStruct(nil, nil)}, SchemaProperty{Type: "object"}},
+		{"time", args{"time.Time", timeTime}, SchemaProperty{Type: "string", Format: "date-time"}},
+		{"duration", args{"time.Duration", timeDuration}, SchemaProperty{Type: "integer", Format: "int64", Description: "nanoseconds"}},
+		{"source text time", args{"*time.Time", nil}, SchemaProperty{Type: "string", Format: "date-time", Nullable: true}},
+		{"bytes", args{"[]byte", types.NewSlice(types.Typ[types.Byte])}, SchemaProperty{Type: "string", Format: "byte"}},
+		{"source text bytes", args{"[]byte", nil}, SchemaProperty{Type: "string", Format: "byte"}},
+		{"ip", args{"net.IP", netIP}, SchemaProperty{Type: "string"}},
+		{"slice", args{"[]string", types.NewSlice(types.Typ[types.String])}, SchemaProperty{Type: "array", Items: &SchemaProperty{Type: "string"}}},
+		{"named slice", args{"Tags", tags}, SchemaProperty{Type: "array", Items: &SchemaProperty{Type: "string"}}},
+		{"array of refs", args{"[2]Tag", types.NewArray(tag, 2)}, SchemaProperty{Type: "array", Items: &SchemaProperty{Ref: "#/components/schemas/Tag"}}},
+		{"map of slices", args{"map[string][]Tag", types.NewMap(types.Typ[types.String], types.NewSlice(tag))}, SchemaProperty{Type: "object", AdditionalProperties: &SchemaProperty{Type: "array", Items: &SchemaProperty{Ref: "#/components/schemas/Tag"}}}},
+		{"partially resolved", args{"[]Tag", types.NewSlice(types.Typ[types.Invalid])}, SchemaProperty{Type: "array", Items: &SchemaProperty{Ref: "#/components/schemas/Tag"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, build.typeProperty(tt.args.fieldType, tt.args.goType))
+		})
+	}
+}
+
+func Test_typeProperty_typeMapping(t *testing.T) {
+	defer func() { config.TypeMappings = map[string]config.TypeMapping{} }()
+	pkg := types.NewPackage("example.com/acme/money", "money")
+	amount := types.NewNamed(types.NewTypeName(0, pkg, "Amount", nil), types.Typ[types.Int64], nil)
+	ulid := types.NewNamed(types.NewTypeName(0, types.NewPackage("github.com/oklog/ulid", "ulid"), "ULID", nil), types.NewArray(types.Typ[types.Byte], 16), nil)
+	assert.Nil(t, config.AddTypeMapping("example.com/acme/money.Amount=type:number;format:decimal;example:10.5"))
+	assert.Nil(t, config.AddTypeMapping("money.Amount=type:string"))
+	config.TypeMappings["github.com/oklog/ulid.ULID"] = config.TypeMapping{Ref: "ULID"}
+	assert.NotNil(t, config.AddTypeMapping("github.com/oklog/ulid.ULID"))
+	assert.NotNil(t, config.AddTypeMapping("github.com/oklog/ulid.ULID=kind:string"))
+	build := &structBuild{refs: map[string]string{"example.com/acme/money.Amount": "Amount"}}
+	// before the refs of marked structs
+	assert.Equal(t, SchemaProperty{Type: "number", Format: "decimal", Example: 10.5}, build.typeProperty("money.Amount", amount))
+	assert.Equal(t, SchemaProperty{Type: "array", Items: &SchemaProperty{Ref: "#/components/schemas/ULID"}}, build.typeProperty("[]ulid.ULID", types.NewSlice(ulid)))
+	assert.Equal(t, SchemaProperty{Type: "string", Nullable: true}, build.typeProperty("*money.Amount", nil))
+}
+
+func Test_typeProperty_marshaler(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "money.go", `package money
+
+type Amount struct{ cents int64 }
+
+func (a Amount) MarshalJSON() ([]byte, error) { return nil, nil }
+
+type Code struct{ code string }
+
+func (c *Code) MarshalText() ([]byte, error) { return nil, nil }
+
+type Currency int
+
+const (
+	USD Currency = iota
+	EUR
+)
+
+func (c Currency) MarshalText() ([]byte, error) { return nil, nil }
+
+type Status string
+
+func (s Status) MarshalText() ([]byte, error) { return nil, nil }
+
+type Other struct{ value string }
+
+func (o Other) MarshalText() string { return "" }
+`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("example.com/acme/money", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(name string) types.Type { return pkg.Scope().Lookup(name).Type() }
+	build := &structBuild{enums: in.Enums{"example.com/acme/money.Currency": {int64(0), int64(1)}, "example.com/acme/money.Status": {"open", "closed"}}}
+	assert.Equal(t, SchemaProperty{}, build.typeProperty("Amount", lookup("Amount")), "json.Marshaler without a type mapping")
+	assert.Equal(t, SchemaProperty{Type: "string"}, build.typeProperty("Code", lookup("Code")), "pointer receiver")
+	assert.Equal(t, SchemaProperty{Type: "string", Nullable: true}, build.typeProperty("*Code", types.NewPointer(lookup("Code"))))
+	assert.Equal(t, SchemaProperty{Type: "string"}, build.typeProperty("Currency", lookup("Currency")), "the int values are not what is encoded")
+	assert.Equal(t, SchemaProperty{Type: "string", Enum: []interface{}{"open", "closed"}}, build.typeProperty("Status", lookup("Status")))
+	assert.Equal(t, SchemaProperty{Type: "object"}, build.typeProperty("Other", lookup("Other")), "not a TextMarshaler")
+	assert.Len(t, build.warned, 1)
+	build.typeProperty("[]Amount", types.NewSlice(lookup("Amount")))
+	assert.Len(t, build.warned, 1)
+	config.TypeMappings["example.com/acme/money.Amount"] = config.TypeMapping{Type: "string", Format: "decimal"}
+	defer func() { config.TypeMappings = map[string]config.TypeMapping{} }()
+	assert.Equal(t, SchemaProperty{Type: "string", Format: "decimal"}, build.typeProperty("Amount", lookup("Amount")))
+}
+
+func TestSchemaProperty_Marshal(t *testing.T) {
+	defer func() { config.OpenApiVersion = "" }()
+	property := SchemaProperty{Type: "string", Nullable: true}
+	config.OpenApiVersion = "3.0.3"
+	out, err := json.Marshal(property)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"type":"string","nullable":true}`, string(out))
+	config.OpenApiVersion = "3.1.0"
+	out, err = json.Marshal(property)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"type":["string","null"]}`, string(out))
+	out, err = yaml.Marshal(SchemaProperty{Type: "integer", Format: "int64", Description: "the id", Nullable: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "type:\n- integer\n- \"null\"\nformat: int64\ndescription: the id\n", string(out))
+	assert.Equal(t, SchemaProperty{AnyOf: []SchemaProperty{{Ref: "#/components/schemas/User"}, {Type: "null"}}}, nullable(SchemaProperty{Ref: "#/components/schemas/User"}))
+	// the exclusive bounds are the numbers in 3.1
+	zero, ten, hundred := 0.0, 10.0, 100.0
+	bounded := SchemaProperty{Type: "integer", Minimum: &zero, ExclusiveMinimum: true, Maximum: &ten, ExclusiveMaximum: true}
+	out, err = json.Marshal(bounded)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"type":"integer","exclusiveMinimum":0,"exclusiveMaximum":10}`, string(out))
+	out, err = yaml.Marshal(SchemaProperty{Type: "number", Minimum: &zero, ExclusiveMinimum: true, Maximum: &hundred, Nullable: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "type:\n- number\n- \"null\"\nexclusiveMinimum: 0\nmaximum: 100\n", string(out))
+	config.OpenApiVersion = "3.0.3"
+	out, err = json.Marshal(bounded)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"type":"integer","minimum":0,"exclusiveMinimum":true,"maximum":10,"exclusiveMaximum":true}`, string(out))
+	out, err = yaml.Marshal(bounded)
+	assert.Nil(t, err)
+	assert.Equal(t, "type: integer\nminimum: 0\nexclusiveMinimum: true\nmaximum: 10\nexclusiveMaximum: true\n", string(out))
+}
+
+func Test_applyConstraints(t *testing.T) {
+	config.ValidateTag = "validate"
+	defer func() { config.ValidateTag = "" }()
+	intPtr := func(i int) *int { return &i }
+	floatPtr := func(f float64) *float64 { return &f }
+	tests := []struct {
+		name         string
+		property     SchemaProperty
+		tag          string
+		want         SchemaProperty
+		wantRequired bool
+	}{
+		{
+			"string",
+			SchemaProperty{Type: "string"},
+			`validate:"required,min=1,max=64,email"`,
+			SchemaProperty{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(64), Format: "email"},
+			true,
+		},
+		{
+			"number",
+			SchemaProperty{Type: "integer"},
+			`validate:"omitempty,gt=0,lte=10"`,
+			SchemaProperty{Type: "integer", Minimum: floatPtr(0), ExclusiveMinimum: true, Maximum: floatPtr(10)},
+			false,
+		},
+		{
+			"oneof",
+			SchemaProperty{Type: "string"},
+			`validate:"oneof=a b c"`,
+			SchemaProperty{Type: "string", Enum: []interface{}{"a", "b", "c"}},
+			false,
+		},
+		{
+			"dive",
+			SchemaProperty{Type: "array", Items: &SchemaProperty{Type: "string"}},
+			`validate:"min=1,dive,alpha,len=2"`,
+			SchemaProperty{Type: "array", MinItems: intPtr(1), Items: &SchemaProperty{Type: "string", Pattern: "^[a-zA-Z]+$", MinLength: intPtr(2), MaxLength: intPtr(2)}},
+			false,
+		},
+		{
+			"sw override",
+			SchemaProperty{Type: "string"},
+			`validate:"max=64,uuid" sw_max:"36" sw_format:"ulid" sw_pattern:"^[0-9A-Z]+$"`,
+			SchemaProperty{Type: "string", MaxLength: intPtr(36), Format: "ulid", Pattern: "^[0-9A-Z]+$"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := structtag.Parse(tt.tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			property := tt.property
+			required := applyConstraints(&property, tags)
+			assert.Equal(t, tt.want, property)
+			assert.Equal(t, tt.wantRequired, required)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package schema
+
+/*
+types that are matched by their package name and type name, these don't resolve to
+the right schema type by looking at their underlying type
+- time.Duration is written by encoding/json as its int64 count of nanoseconds
+- net.IP may be an ipv4 or an ipv6 address, validate:"ipv4" or sw_format:"ipv6" narrows it down
+*/
+var namedTypes = map[string]SchemaProperty{
+	"time.Time":       {Type: "string", Format: "date-time"},
+	"time.Duration":   {Type: "integer", Format: "int64", Description: "nanoseconds"},
+	"uuid.UUID":       {Type: "string", Format: "uuid"},
+	"net.IP":          {Type: "string"},
+	"url.URL":         {Type: "string", Format: "uri"},
+	"decimal.Decimal": {Type: "string", Format: "decimal"},
+	"json.RawMessage": {}, // any json
+}
+
+// types that wrap a value that may be null (database/sql and gopkg.in/guregu/null), matched by package name and type name
+var nullTypes = map[string]SchemaProperty{
+	"sql.NullString":      {Type: "string"},
+	"sql.NullInt64":       {Type: "integer", Format: "int64"},
+	"sql.NullInt32":       {Type: "integer", Format: "int32"},
+	"sql.NullInt16":       {Type: "integer", Format: "int32"},
+	"sql.NullByte":        {Type: "integer", Format: "int32"},
+	"sql.NullFloat64":     {Type: "number", Format: "double"},
+	"sql.NullBool":        {Type: "boolean"},
+	"sql.NullTime":        {Type: "string", Format: "date-time"},
+	"null.String":         {Type: "string"},
+	"null.Int":            {Type: "integer", Format: "int64"},
+	"null.Int32":          {Type: "integer", Format: "int32"},
+	"null.Int16":          {Type: "integer", Format: "int32"},
+	"null.Byte":           {Type: "integer", Format: "int32"},
+	"null.Float":          {Type: "number", Format: "double"},
+	"null.Bool":           {Type: "boolean"},
+	"null.Time":           {Type: "string", Format: "date-time"},
+	"uuid.NullUUID":       {Type: "string", Format: "uuid"},
+	"decimal.NullDecimal": {Type: "string", Format: "decimal"},
+}
+
+// generic types that wrap a value that may be null, i.e. Optional[T], matched by type name or package name and type name
+var nullGenerics = map[string]struct{}{
+	"Optional":   {},
+	"Nullable":   {},
+	"Option":     {},
+	"Null":       {},
+	"sql.Null":   {},
+	"null.Value": {},
+}
@@ -30,7 +30,7 @@ repeat @@name
 */
 
 func BuildSecurity(comments in.SwagifyComment) map[string][]map[string][]string {
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z]+): *?(?P<value>.+)")
 	securityMap := make(map[string][]map[string][]string)
 	for name, lineArray := range comments.Comments {
 		for _, lines := range lineArray {
@@ -63,7 +63,7 @@ func BuildSecurity(comments in.SwagifyComment) map[string][]map[string][]string
 }
 
 func BuildSecuritySchemes(comments in.SwagifyComment) map[string]SecurityScheme {
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z]+): *?(?P<value>.+)")
 	securitySchemeMap := make(map[string]SecurityScheme)
 	securityScheme := SecurityScheme{}
 	for name, lineArray := range comments.Comments {
@@ -26,7 +26,7 @@ type (
 */
 
 func BuildServers(comments in.SwagifyComment) map[string][]Server {
-	reg := regexp.MustCompile("(?P<name>[a-zA-Z]+): *?(?P<value>.+)")
+	reg := regexp.MustCompile("(?s)(?P<name>[a-zA-Z]+): *?(?P<value>.+)")
 	serverMap := make(map[string][]Server)
 	for name, lineArray := range comments.Comments {
 		for _, lines := range lineArray {
@@ -1,80 +1,360 @@
 package internal
 
 import (
+	"bufio"
 	"fmt"
 	"go/ast"
-	"go/parser"
+	"go/constant"
 	"go/token"
-	"io/ioutil"
+	"go/types"
 	"os"
-	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/blackflagsoftware/go-swagify/config"
+	perr "github.com/blackflagsoftware/go-swagify/internal/parseerror"
 )
 
 type (
 	MyStruct struct {
-		Name   string
-		Fields []MyField
+		Name       string
+		Pkg        string // import path of the struct's package
+		Fields     []MyField
+		TypeParams []string   // names of the type parameters of a generic struct, i.e. Page[T any] => [T]
+		GoType     types.Type // type checked (defined) type of the struct, nil if it could not be resolved
+		Alias      string     // name of the struct's schema, @@struct: User as BillingUser
+		Auto       bool       // all of the exported fields are in the struct's schema, @@auto: true
+		Doc        string     // doc comment of the struct, without the go-swagify comments
+		Pos        string     // file:line of the struct
 	}
 
 	MyField struct {
-		Name string
-		Type string
-		Tag  string
+		Name   string
+		Type   string     // source text of the field's type
+		Tag    string     // raw struct tag, without the back quotes
+		GoType types.Type // type checked type, nil if it could not be resolved
+		// anonymous field, Name is the name of the type
+		Embedded bool
+		Doc      string // doc comment (or line comment) of the field
 	}
+
+	// named type (import path + name) => the values of its constants, in the order they are declared
+	Enums map[string][]interface{}
 )
 
+// import path + name, matches the string of the struct's go/types type, i.e. example.com/acme/user.User
+func (m MyStruct) TypeName() string {
+	if m.Pkg == "" {
+		return m.Name
+	}
+	return m.Pkg + "." + m.Name
+}
+
+// all of the structs of the file, with all of their fields, see Source.MarkedStructs
+func inspectStructs(parsedFile *ast.File, pkgPath string, info *types.Info, position func(token.Pos) string) (myStructs []MyStruct) {
+	var genDecl *ast.GenDecl
+	ast.Inspect(parsedFile, func(n ast.Node) bool {
+		switch t := n.(type) {
+		case *ast.GenDecl:
+			genDecl = t
+		case *ast.TypeSpec:
+			if s, ok := t.Type.(*ast.StructType); ok {
+				myStruct := MyStruct{Name: t.Name.Name, Pkg: pkgPath, Doc: docText(t.Doc), Pos: position(t.Pos())}
+				if myStruct.Doc == "" && genDecl != nil && !genDecl.Lparen.IsValid() {
+					// type User struct {...}, not in a type (...) group, the comment is the GenDecl's
+					myStruct.Doc = docText(genDecl.Doc)
+				}
+				if obj := info.Defs[t.Name]; obj != nil {
+					myStruct.GoType = obj.Type()
+				}
+				if t.TypeParams != nil {
+					for _, typeParam := range t.TypeParams.List {
+						for _, name := range typeParam.Names {
+							myStruct.TypeParams = append(myStruct.TypeParams, name.Name)
+						}
+					}
+				}
+				for _, field := range s.Fields.List {
+					tag := ""
+					if field.Tag != nil {
+						tag = unquoteTag(field.Tag.Value)
+					}
+					if len(field.Names) == 0 {
+						myStruct.Fields = append(myStruct.Fields, MyField{
+							Name:     embeddedName(field.Type),
+							Type:     types.ExprString(field.Type),
+							Tag:      tag,
+							GoType:   info.TypeOf(field.Type),
+							Embedded: true,
+						})
+						continue
+					}
+					doc := fieldDoc(field)
+					for _, name := range field.Names {
+						myStruct.Fields = append(myStruct.Fields, MyField{
+							Name:   name.Name,
+							Type:   types.ExprString(field.Type),
+							Tag:    tag,
+							GoType: info.TypeOf(field.Type),
+							Doc:    doc,
+						})
+					}
+				}
+				myStructs = append(myStructs, myStruct)
+			}
+		}
+		return true
+	})
+	return
+}
+
 /*
-this will take a list of "marked" (through comments) to parse and create a MyStruct structure
-based on the struct's content, used by "schema", see internal/schema/schema.go
+the struct is marked by @@struct: <name>, optionally
+- with its type parameters, if it is generic, i.e. @@struct: Page[T any]
+- with its package name or import path, when more than one package has the name, i.e. @@struct: billing.User
+- with the name of its schema, i.e. @@struct: User as BillingUser
+- with all of its fields, @@auto: true (or -auto), see internal/schema/auto.go
 */
-func ParseDirForStructs(directory string, comments SwagifyComment) (myStructs []MyStruct) {
-	// only want to deal with .go files
-	dirItems, errReadDir := os.ReadDir(directory)
-	if errReadDir != nil {
-		fmt.Println("Error reading diretory:", directory)
-		return
-	}
-	for _, di := range dirItems {
-		file := path.Join(directory, di.Name())
-		if di.IsDir() {
-			myStructs = append(myStructs, ParseDirForStructs(file, comments)...)
-			continue
+func markedStruct(comments SwagifyComment, pkgName, pkgPath, name string) (marked bool, alias string, auto bool) {
+	// the alias of the most specific marker is used, i.e. billing.User over User
+	specific := -1
+	for comment, lineArray := range comments.Comments {
+		marker, as := structMarker(comment)
+		if i := markerMatch(marker, pkgName, pkgPath, name); i > -1 {
+			marked = true
+			if as != "" && (i > specific || (i == specific && as < alias)) {
+				specific, alias = i, as
+			}
+			auto = auto || autoLine(lineArray)
 		}
-		if path.Ext(di.Name()) != ".go" {
-			continue
+	}
+	auto = auto || config.AutoStructs
+	return
+}
+
+// the struct's name of the marker and its alias, i.e. billing.Page[T any] as BillingPage => billing.Page, BillingPage
+func structMarker(comment string) (marker, as string) {
+	marker = comment
+	if split := strings.SplitN(comment, " as ", 2); len(split) == 2 {
+		marker, as = strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+	}
+	if idx := strings.Index(marker, "["); idx > -1 {
+		marker = strings.TrimSpace(marker[:idx])
+	}
+	return
+}
+
+// how specific the marker is of the struct: 0 the name, 1 package name + name, 2 import path + name, -1 not the struct's
+func markerMatch(marker, pkgName, pkgPath, name string) int {
+	for i, n := range []string{name, pkgName + "." + name, pkgPath + "." + name} {
+		if marker == n {
+			return i
 		}
-		src, err := ioutil.ReadFile(file)
-		if err != nil {
-			fmt.Println("readfile:", err)
-			break
+	}
+	return -1
+}
+
+// @@auto: true
+func autoLine(lineArray [][]string) bool {
+	for _, lines := range lineArray {
+		for _, line := range lines {
+			if split := strings.SplitN(line, ":", 2); len(split) == 2 && strings.TrimSpace(split[0]) == "auto" {
+				if auto, err := strconv.ParseBool(strings.TrimSpace(split[1])); err == nil {
+					return auto
+				}
+				perr.AddError(fmt.Sprintf("[Warning] @@struct: invalid @@auto: %s, expected true or false", strings.TrimSpace(split[1])))
+			}
 		}
-		fset := token.NewFileSet()
-		parsedFile, err := parser.ParseFile(fset, file, src, 0)
-		if err != nil {
-			fmt.Println("Error in parsing file:", file)
-			return
+	}
+	return false
+}
+
+// the constants declared with a named type, i.e. const StatusOpen OrderStatus = "open"
+func inspectConsts(parsedFile *ast.File, info *types.Info, position func(token.Pos) string) (consts []ConstDecl) {
+	for _, decl := range parsedFile.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
 		}
-		ast.Inspect(parsedFile, func(n ast.Node) bool {
-			switch t := n.(type) {
-			case *ast.TypeSpec:
-				if s, ok := t.Type.(*ast.StructType); ok {
-					if _, foundStruct := comments.Comments[t.Name.Name]; foundStruct {
-						myStruct := MyStruct{Name: t.Name.Name}
-						for _, field := range s.Fields.List {
-							if len(field.Names) > 0 && field.Tag != nil {
-								myStruct.Fields = append(myStruct.Fields, MyField{
-									Name: field.Names[0].Name,
-									Type: string(src[field.Type.Pos()-1 : field.Type.End()-1]),
-									Tag:  string(src[field.Tag.Pos() : field.Tag.End()-2]),
-								})
-							}
-						}
-						myStructs = append(myStructs, myStruct)
-					}
+		for _, spec := range genDecl.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				c, ok := info.Defs[name].(*types.Const)
+				if !ok || name.Name == "_" {
+					continue
+				}
+				named, ok := c.Type().(*types.Named)
+				if !ok {
+					continue
+				}
+				if value := constValue(c.Val()); value != nil {
+					consts = append(consts, ConstDecl{Name: name.Name, Type: named.String(), Value: value, Pos: position(name.Pos())})
 				}
 			}
-			return true
-		})
+		}
 	}
 	return
 }
+
+func constValue(value constant.Value) interface{} {
+	switch value.Kind() {
+	case constant.String:
+		return constant.StringVal(value)
+	case constant.Bool:
+		return constant.BoolVal(value)
+	case constant.Int:
+		if i, exact := constant.Int64Val(value); exact {
+			return i
+		}
+	case constant.Float:
+		f, _ := constant.Float64Val(value)
+		return f
+	}
+	return nil
+}
+
+/*
+the values of a named type by its full name (import path + name) or, when only one type matches,
+by package name + name or just the name, i.e. orders.OrderStatus or OrderStatus
+*/
+func (e Enums) Lookup(name string) ([]interface{}, bool) {
+	if values, ok := e[name]; ok {
+		return values, true
+	}
+	found := []string{}
+	for key := range e {
+		if strings.HasSuffix(key, "/"+name) || strings.HasSuffix(key, "."+name) {
+			found = append(found, key)
+		}
+	}
+	if len(found) != 1 {
+		return nil, false
+	}
+	return e[found[0]], true
+}
+
+// type check the files of one package, errors are not fatal, the fields that can't be resolved
+// will not have a GoType and fall back to the source text of the type, the first one is the warning,
+// the package is for the packages that import it
+func checkPackage(fset *token.FileSet, importer types.Importer, pkgPath, pkgName string, files []*ast.File) (pkg *types.Package, info *types.Info, warning string) {
+	info = &types.Info{Types: make(map[ast.Expr]types.TypeAndValue), Defs: make(map[*ast.Ident]types.Object)}
+	var firstErr error
+	conf := types.Config{
+		Importer: importer,
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	pkg, _ = conf.Check(pkgPath, fset, files, info)
+	if firstErr != nil {
+		warning = fmt.Sprintf("[Warning] @@struct: unable to fully type check package %s: %s", pkgName, firstErr)
+	}
+	return
+}
+
+// determine the import path of the directory from the closest go.mod, the package name is used if none is found
+func packagePath(directory, pkgName string) string {
+	absDir, err := filepath.Abs(directory)
+	if err != nil {
+		return pkgName
+	}
+	for dir := absDir; ; dir = filepath.Dir(dir) {
+		if modPath := modulePath(filepath.Join(dir, "go.mod")); modPath != "" {
+			rel, err := filepath.Rel(dir, absDir)
+			if err != nil || rel == "." {
+				return modPath
+			}
+			return modPath + "/" + filepath.ToSlash(rel)
+		}
+		if filepath.Dir(dir) == dir {
+			return pkgName
+		}
+	}
+}
+
+func modulePath(goMod string) string {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), "\"")
+		}
+	}
+	return ""
+}
+
+/*
+the fields of a struct type, as if they had been parsed from source, used for the fields of embedded structs
+that are not marked, nil if goType is not a struct (or pointer to)
+*/
+func StructFields(goType types.Type) (fields []MyField) {
+	if pointer, ok := goType.(*types.Pointer); ok {
+		goType = pointer.Elem()
+	}
+	s, ok := goType.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	qualifier := func(pkg *types.Package) string { return pkg.Name() }
+	for i := 0; i < s.NumFields(); i++ {
+		v := s.Field(i)
+		fields = append(fields, MyField{
+			Name:     v.Name(),
+			Type:     types.TypeString(v.Type(), qualifier),
+			Tag:      s.Tag(i),
+			GoType:   v.Type(),
+			Embedded: v.Embedded(),
+		})
+	}
+	return fields
+}
+
+// the field name of an embedded field is its type's name, i.e. *models.Base => Base
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return types.ExprString(expr)
+}
+
+// the doc comment of the field, or its line comment if it has none
+func fieldDoc(field *ast.Field) string {
+	if doc := docText(field.Doc); doc != "" {
+		return doc
+	}
+	return docText(field.Comment)
+}
+
+// the text of the comments, the go-swagify comments are left out
+func docText(commentGroup *ast.CommentGroup) string {
+	doc := &ast.CommentGroup{}
+	for _, block := range commentBlocks(commentGroup) {
+		if !IsSwagifyComment(blockText(block, nil)) {
+			doc.List = append(doc.List, block...)
+		}
+	}
+	return strings.TrimSpace(doc.Text())
+}
+
+func unquoteTag(tag string) string {
+	if unquoted, err := strconv.Unquote(tag); err == nil {
+		return unquoted
+	}
+	return strings.Trim(tag, "`")
+}
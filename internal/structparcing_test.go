@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestSource_MarkedStructs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod": "module example.com/acme\n\ngo 1.18\n",
+		"user/user.go": `package user
+
+type UserID int64
+
+type Status string
+
+const (
+	StatusActive Status = "active"
+	StatusClosed Status = "closed"
+	Other               = "other"
+)
+
+type Level int
+
+const (
+	LevelLow Level = iota + 1
+	LevelHigh
+	LevelDefault = LevelLow
+)
+
+type User struct {
+	ID   UserID ` + "`json:\"id\" sw:\"User\"`" + `
+	Name string ` + "`json:\"name\" sw:\"User\"`" + `
+	skip string
+}
+
+type Page[T any, K comparable] struct {
+	Items []T ` + "`json:\"items\" sw:\"Page\"`" + `
+}
+`,
+	})
+	comments := SwagifyComment{Comments: map[string][][]string{"User": {{}}, "Page[T any, K comparable]": {{}}}}
+	source := ScanDir(dir)
+	myStructs, enums := source.MarkedStructs(comments), source.Enums()
+	assert.Equal(t, Enums{
+		"example.com/acme/user.Status": {"active", "closed"},
+		"example.com/acme/user.Level":  {int64(1), int64(2)},
+	}, enums)
+	values, ok := enums.Lookup("user.Status")
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"active", "closed"}, values)
+	if !assert.Len(t, myStructs, 2) {
+		return
+	}
+	assert.Equal(t, "Page", myStructs[1].Name)
+	assert.Equal(t, []string{"T", "K"}, myStructs[1].TypeParams)
+	assert.Equal(t, "example.com/acme/user.Page[T any, K comparable]", myStructs[1].GoType.String())
+	assert.Equal(t, "User", myStructs[0].Name)
+	if !assert.Len(t, myStructs[0].Fields, 2) {
+		return
+	}
+	id := myStructs[0].Fields[0]
+	assert.Equal(t, "UserID", id.Type)
+	assert.Equal(t, `json:"id" sw:"User"`, id.Tag)
+	if assert.NotNil(t, id.GoType) {
+		assert.Equal(t, "example.com/acme/user.UserID", id.GoType.String())
+	}
+}
+
+func Test_markedStruct(t *testing.T) {
+	comments := SwagifyComment{Comments: map[string][][]string{
+		"User":                        {{}},
+		"billing.User as BillingUser": {{}},
+		"Page[T any] as Paged":        {{"auto: true"}},
+		"example.com/acme/shop.Item":  {{}},
+	}}
+	tests := []struct {
+		name       string
+		pkgName    string
+		pkgPath    string
+		structName string
+		marked     bool
+		alias      string
+		auto       bool
+	}{
+		{"bare", "user", "example.com/acme/user", "User", true, "", false},
+		{"package name alias", "billing", "example.com/acme/billing", "User", true, "BillingUser", false},
+		{"generic alias, auto", "page", "example.com/acme/page", "Page", true, "Paged", true},
+		{"import path", "shop", "example.com/acme/shop", "Item", true, "", false},
+		{"other package", "stock", "example.com/acme/stock", "Item", false, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marked, alias, auto := markedStruct(comments, tt.pkgName, tt.pkgPath, tt.structName)
+			assert.Equal(t, tt.marked, marked)
+			assert.Equal(t, tt.alias, alias)
+			assert.Equal(t, tt.auto, auto)
+		})
+	}
+}
+
+func TestSource_MarkedStructs_auto(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod": "module example.com/acme\n\ngo 1.18\n",
+		"dto/dto.go": `package dto
+
+// Address of a customer.
+/* go-swagify
+@@struct: Address
+@@auto: true
+*/
+type Address struct {
+	// the street and number
+	Street string ` + "`json:\"street\"`" + `
+	City, Zip string // the city and zip
+	Country string
+}
+`,
+	})
+	myStructs := ScanDir(dir).MarkedStructs(SwagifyComment{Comments: map[string][][]string{"Address": {{"auto: true"}}}})
+	if !assert.Len(t, myStructs, 1) {
+		return
+	}
+	assert.True(t, myStructs[0].Auto)
+	assert.Equal(t, "Address of a customer.", myStructs[0].Doc)
+	fields := myStructs[0].Fields
+	if !assert.Len(t, fields, 4) {
+		return
+	}
+	assert.Equal(t, "the street and number", fields[0].Doc)
+	assert.Equal(t, "City", fields[1].Name)
+	assert.Equal(t, "Zip", fields[2].Name)
+	assert.Equal(t, "the city and zip", fields[2].Doc)
+	assert.Equal(t, "", fields[3].Tag)
+}
+
+func TestSource_MarkedStructs_lineComments(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"go.mod": "module example.com/acme\n\ngo 1.18\n",
+		"user/user.go": `package user
+
+// go-swagify
+// @@struct: User
+// User is a user.
+type User struct {
+	Name string ` + "`json:\"name\" sw:\"User\"`" + `
+}
+
+// go-swagify
+// @@struct: Order
+//   as Purchase
+type Order struct {
+	ID int ` + "`json:\"id\" sw:\"Purchase\"`" + `
+}
+`,
+	})
+	source := ScanDir(dir)
+	component := ParseSwagifyBlocks(source.Blocks)
+	// the doc comment after the entries is not the marker's value, the indented line is
+	assert.Contains(t, component.Types["struct"].Comments, "User")
+	myStructs := source.MarkedStructs(component.Types["struct"])
+	if !assert.Len(t, myStructs, 2) {
+		return
+	}
+	assert.Equal(t, "User", myStructs[0].Name)
+	assert.Equal(t, "Order", myStructs[1].Name)
+	assert.Equal(t, "Purchase", myStructs[1].Alias)
+}